@@ -32,12 +32,14 @@ import (
 	"github.com/x-cray/logrus-prefixed-formatter"
 
 	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/console"
 
 	"github.com/haakenlabs/forge/cmd/forge/scene"
 )
 
 var (
-	debug bool
+	debug       bool
+	consoleAddr string
 )
 
 func init() {
@@ -54,6 +56,7 @@ func init() {
 // parseArgs parses command line arguments.
 func parseArgs() {
 	flag.BoolVar(&debug, "d", false, "enable debug mode")
+	flag.StringVar(&consoleAddr, "console", "", "serve the debug console on a local address (tcp:127.0.0.1:7070 or unix:/path)")
 
 	flag.Parse()
 
@@ -69,6 +72,14 @@ func makeApp() *engine.App {
 		Name: "Forge",
 	})
 
+	// Serve the debug console if requested.
+	if consoleAddr != "" {
+		a.SetPreSetup(func() error {
+			a.RegisterSystem(console.NewServer(a, consoleAddr))
+			return nil
+		})
+	}
+
 	// Set the PostSetup func.
 	a.SetPostSetup(func() error {
 		// Make the scenes.
@@ -0,0 +1,13 @@
+#ifdef _FRAGMENT_
+
+uniform float f_fade = 0.0;
+
+subroutine(RenderPassType)
+vec4 pass_0()
+{
+    vec4 color = texture(u_source, vo_texture);
+
+    return vec4(color.rgb * (1.0 - f_fade), color.a);
+}
+
+#endif
@@ -0,0 +1,7 @@
+{
+    "name": "effect/fade",
+    "files": [
+        "../utils/base.glsl",
+        "fade.glsl"
+    ]
+}
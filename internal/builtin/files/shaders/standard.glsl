@@ -18,13 +18,17 @@ uniform mat3 v_normal_matrix;
 
 void main()
 {
+    mat4 skin = skin_matrix();
+    vec4 position = skin * vec4(vertex, 1.0);
+    vec3 object_normal = normalize(mat3(skin) * normal);
+
     vo_texture = uv;
-    vo_normal = normal;// normalize(v_normal_matrix * normal);
-    vo_position = vertex;
-    vo_ws_position = vec3(v_model_matrix * vec4(vertex, 1.0));
-    vo_ws_normal = vec3(v_model_matrix * vec4(normal, 1.0));
+    vo_normal = object_normal;// normalize(v_normal_matrix * normal);
+    vo_position = position.xyz;
+    vo_ws_position = vec3(v_model_matrix * position);
+    vo_ws_normal = vec3(v_model_matrix * vec4(object_normal, 0.0));
 
-    gl_Position = v_projection_matrix * v_view_matrix * v_model_matrix * vec4(vertex, 1.0);
+    gl_Position = v_projection_matrix * v_view_matrix * v_model_matrix * position;
 }
 
 #endif
@@ -0,0 +1,34 @@
+#ifdef _VERTEX_
+layout(location = 0) in vec3 vertex;
+layout(location = 1) in vec3 normal;
+layout(location = 2) in vec2 uv;
+
+out vec3 vo_normal;
+
+uniform mat4 v_projection_matrix;
+uniform mat4 v_view_matrix;
+uniform mat4 v_model_matrix;
+
+void main()
+{
+    mat4 skin = skin_matrix();
+    mat4 model_view = v_view_matrix * v_model_matrix;
+
+    vo_normal = normalize(mat3(model_view) * mat3(skin) * normal);
+
+    gl_Position = v_projection_matrix * model_view * skin * vec4(vertex, 1.0);
+}
+
+#endif
+
+#ifdef _FRAGMENT_
+in vec3 vo_normal;
+
+layout(location = 0) out vec4 fo_normal;
+
+void main()
+{
+    fo_normal = vec4(normalize(vo_normal), 1.0);
+}
+
+#endif
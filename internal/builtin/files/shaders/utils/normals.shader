@@ -0,0 +1,7 @@
+{
+    "name": "utils/normals",
+    "files": [
+        "skinning.glsl",
+        "normals.glsl"
+    ]
+}
@@ -0,0 +1,22 @@
+#ifdef _VERTEX_
+#define MAX_JOINTS 64
+
+layout(location = 3) in uvec4 joints;
+layout(location = 4) in vec4 weights;
+
+uniform bool v_skinned;
+uniform mat4 v_joint_matrices[MAX_JOINTS];
+
+mat4 skin_matrix()
+{
+    if (!v_skinned) {
+        return mat4(1.0);
+    }
+
+    return weights.x * v_joint_matrices[joints.x]
+         + weights.y * v_joint_matrices[joints.y]
+         + weights.z * v_joint_matrices[joints.z]
+         + weights.w * v_joint_matrices[joints.w];
+}
+
+#endif
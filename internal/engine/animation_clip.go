@@ -0,0 +1,435 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Animation errors
+const (
+	ErrAnimationPath      = Error("animation path must have the form object/path:Component.property")
+	ErrAnimationNoKeys    = Error("animation curve has no keyframes")
+	ErrAnimationKeyOrder  = Error("animation keyframes are not sorted by time")
+	ErrAnimationValueSize = Error("animation keyframe values differ in size")
+)
+
+// Interpolation is the interpolation between the keyframes of a curve.
+type Interpolation int
+
+const (
+	InterpolationLinear Interpolation = iota
+	InterpolationStep
+	InterpolationCubic
+)
+
+// Keyframe is a value of a curve at a point in time. The tangents of cubic
+// curves are in units per second. Missing tangents are computed from the
+// neighbouring keyframes.
+type Keyframe struct {
+	Time       float64   `json:"time"`
+	Value      []float32 `json:"value"`
+	InTangent  []float32 `json:"in_tangent,omitempty"`
+	OutTangent []float32 `json:"out_tangent,omitempty"`
+}
+
+// AnimationCurve is a keyframed curve bound to a component property by path.
+// Paths have the form "child/arm:Transform.rotation", where the object path is
+// relative to the animated GameObject and empty for the object itself.
+type AnimationCurve struct {
+	Path          string
+	Interpolation Interpolation
+	Keys          []Keyframe
+
+	object    string
+	component string
+	property  string
+}
+
+// AnimationEventMetadata is an event fired by an Animator when playback passes
+// its time.
+type AnimationEventMetadata struct {
+	Time float64 `json:"time"`
+	Name string  `json:"name"`
+	Data string  `json:"data,omitempty"`
+}
+
+// AnimationClip is a set of curves and events.
+type AnimationClip struct {
+	BaseObject
+
+	curves []*AnimationCurve
+	events []AnimationEventMetadata
+	length float64
+	loop   bool
+}
+
+// AnimationProperty describes an animatable property of a component type.
+type AnimationProperty struct {
+	// Size is the number of values of the property.
+	Size int
+
+	// Rotation marks quaternion properties stored as W, X, Y, Z, which are
+	// normalized when interpolated and blended.
+	Rotation bool
+
+	Get func(c Component) []float32
+	Set func(c Component, value []float32)
+}
+
+// AnimationPropertyResolver returns the animatable property with the given
+// name. It is used for properties which are not known in advance, like the
+// morph target weights of a renderer.
+type AnimationPropertyResolver func(property string) (*AnimationProperty, bool)
+
+var (
+	animationProperties   = make(map[string]*AnimationProperty)
+	animationResolvers    = make(map[string]AnimationPropertyResolver)
+	animationPropertiesMu = &sync.RWMutex{}
+)
+
+func init() {
+	RegisterAnimationProperty("Transform", "position", &AnimationProperty{
+		Size: 3,
+		Get: func(c Component) []float32 {
+			p := c.(Transform).Position()
+			return p[:]
+		},
+		Set: func(c Component, v []float32) {
+			c.(Transform).SetPosition(mgl32.Vec3{v[0], v[1], v[2]})
+		},
+	})
+	RegisterAnimationProperty("Transform", "rotation", &AnimationProperty{
+		Size:     4,
+		Rotation: true,
+		Get: func(c Component) []float32 {
+			r := c.(Transform).Rotation()
+			return []float32{r.W, r.V[0], r.V[1], r.V[2]}
+		},
+		Set: func(c Component, v []float32) {
+			c.(Transform).SetRotation(mgl32.Quat{W: v[0], V: mgl32.Vec3{v[1], v[2], v[3]}})
+		},
+	})
+	RegisterAnimationProperty("Transform", "scale", &AnimationProperty{
+		Size: 3,
+		Get: func(c Component) []float32 {
+			s := c.(Transform).Scale()
+			return s[:]
+		},
+		Set: func(c Component, v []float32) {
+			c.(Transform).SetScale(mgl32.Vec3{v[0], v[1], v[2]})
+		},
+	})
+	RegisterAnimationProperty("Camera", "fov", &AnimationProperty{
+		Size: 1,
+		Get: func(c Component) []float32 {
+			return []float32{c.(*Camera).Fov()}
+		},
+		Set: func(c Component, v []float32) {
+			c.(*Camera).SetFov(v[0])
+		},
+	})
+}
+
+// RegisterAnimationProperty makes a property of a component type animatable.
+// The component type is the registered component type name, or "Transform".
+func RegisterAnimationProperty(component, property string, p *AnimationProperty) {
+	animationPropertiesMu.Lock()
+	defer animationPropertiesMu.Unlock()
+
+	animationProperties[component+"."+property] = p
+}
+
+// RegisterAnimationPropertyResolver makes the properties of a component type
+// which are not registered by name animatable through the resolver.
+func RegisterAnimationPropertyResolver(component string, fn AnimationPropertyResolver) {
+	animationPropertiesMu.Lock()
+	defer animationPropertiesMu.Unlock()
+
+	animationResolvers[component] = fn
+}
+
+// FindAnimationProperty returns the animatable property of a component type.
+// Registered properties take precedence over the resolver of the type.
+func FindAnimationProperty(component, property string) (*AnimationProperty, bool) {
+	animationPropertiesMu.RLock()
+	p, ok := animationProperties[component+"."+property]
+	fn := animationResolvers[component]
+	animationPropertiesMu.RUnlock()
+
+	if ok || fn == nil {
+		return p, ok
+	}
+
+	return fn(property)
+}
+
+// ParseAnimationPath splits an animation path into the object path, the
+// component type and the property.
+func ParseAnimationPath(path string) (object, component, property string, err error) {
+	i := strings.LastIndex(path, ":")
+	if i < 0 {
+		return "", "", "", ErrAnimationPath
+	}
+
+	object = path[:i]
+
+	j := strings.Index(path[i+1:], ".")
+	if j <= 0 || i+j+2 >= len(path) {
+		return "", "", "", ErrAnimationPath
+	}
+
+	return object, path[i+1 : i+1+j], path[i+j+2:], nil
+}
+
+// ObjectPath returns the object path of the curve.
+func (c *AnimationCurve) ObjectPath() string {
+	return c.object
+}
+
+// Component returns the component type the curve is bound to.
+func (c *AnimationCurve) Component() string {
+	return c.component
+}
+
+// Property returns the property the curve is bound to.
+func (c *AnimationCurve) Property() string {
+	return c.property
+}
+
+// Size returns the number of values of the curve.
+func (c *AnimationCurve) Size() int {
+	return len(c.Keys[0].Value)
+}
+
+// Duration returns the time of the last keyframe.
+func (c *AnimationCurve) Duration() float64 {
+	return c.Keys[len(c.Keys)-1].Time
+}
+
+// Evaluate writes the value of the curve at time t to out, which must have the
+// size of the curve. Times outside the keyframes hold the first or last value.
+func (c *AnimationCurve) Evaluate(t float64, out []float32) {
+	keys := c.Keys
+	n := len(keys)
+
+	if t <= keys[0].Time || n == 1 {
+		copy(out, keys[0].Value)
+		return
+	}
+	if t >= keys[n-1].Time {
+		copy(out, keys[n-1].Value)
+		return
+	}
+
+	// Index of the first keyframe after t.
+	i := sort.Search(n, func(i int) bool { return keys[i].Time > t })
+	k0, k1 := &keys[i-1], &keys[i]
+
+	dt := k1.Time - k0.Time
+	s := (t - k0.Time) / dt
+
+	switch c.Interpolation {
+	case InterpolationStep:
+		copy(out, k0.Value)
+	case InterpolationCubic:
+		s2 := s * s
+		s3 := s2 * s
+
+		h00 := float32(2*s3 - 3*s2 + 1)
+		h10 := float32((s3 - 2*s2 + s) * dt)
+		h01 := float32(-2*s3 + 3*s2)
+		h11 := float32((s3 - s2) * dt)
+
+		for j := range out {
+			out[j] = h00*k0.Value[j] + h10*k0.OutTangent[j] + h01*k1.Value[j] + h11*k1.InTangent[j]
+		}
+	default:
+		for j := range out {
+			out[j] = k0.Value[j] + (k1.Value[j]-k0.Value[j])*float32(s)
+		}
+	}
+}
+
+// prepare parses the path, validates the keyframes and computes missing
+// tangents of cubic curves.
+func (c *AnimationCurve) prepare() error {
+	var err error
+	if c.object, c.component, c.property, err = ParseAnimationPath(c.Path); err != nil {
+		return err
+	}
+
+	if len(c.Keys) == 0 {
+		return ErrAnimationNoKeys
+	}
+
+	size := len(c.Keys[0].Value)
+	for i := range c.Keys {
+		if len(c.Keys[i].Value) != size {
+			return ErrAnimationValueSize
+		}
+		if i > 0 && c.Keys[i].Time < c.Keys[i-1].Time {
+			return ErrAnimationKeyOrder
+		}
+	}
+
+	if c.Interpolation != InterpolationCubic {
+		return nil
+	}
+
+	for i := range c.Keys {
+		k := &c.Keys[i]
+		if len(k.InTangent) == size && len(k.OutTangent) == size {
+			continue
+		}
+
+		// Catmull-Rom tangent, one sided at the ends.
+		prev, next := &c.Keys[max(i-1, 0)], &c.Keys[min(i+1, len(c.Keys)-1)]
+		tangent := make([]float32, size)
+		if dt := next.Time - prev.Time; dt > 0 {
+			for j := range tangent {
+				tangent[j] = (next.Value[j] - prev.Value[j]) / float32(dt)
+			}
+		}
+
+		if len(k.InTangent) != size {
+			k.InTangent = tangent
+		}
+		if len(k.OutTangent) != size {
+			k.OutTangent = tangent
+		}
+	}
+
+	return nil
+}
+
+// Curves returns the curves of the clip.
+func (a *AnimationClip) Curves() []*AnimationCurve {
+	return a.curves
+}
+
+// Events returns the events of the clip, sorted by time.
+func (a *AnimationClip) Events() []AnimationEventMetadata {
+	return a.events
+}
+
+// Length returns the length of the clip in seconds.
+func (a *AnimationClip) Length() float64 {
+	return a.length
+}
+
+// SetLength sets the length of the clip in seconds.
+func (a *AnimationClip) SetLength(length float64) {
+	a.length = length
+}
+
+// Loop reports if the clip loops.
+func (a *AnimationClip) Loop() bool {
+	return a.loop
+}
+
+// SetLoop sets if the clip loops.
+func (a *AnimationClip) SetLoop(loop bool) {
+	a.loop = loop
+}
+
+// AddCurve adds a curve to the clip. The clip length is extended to the last
+// keyframe of the curve.
+func (a *AnimationClip) AddCurve(path string, interpolation Interpolation, keys ...Keyframe) (*AnimationCurve, error) {
+	c := &AnimationCurve{
+		Path:          path,
+		Interpolation: interpolation,
+		Keys:          keys,
+	}
+
+	if err := c.prepare(); err != nil {
+		return nil, err
+	}
+
+	a.curves = append(a.curves, c)
+	a.length = math.Max(a.length, c.Duration())
+
+	return c, nil
+}
+
+// AddEvent adds an event fired when playback passes the given time.
+func (a *AnimationClip) AddEvent(time float64, name, data string) {
+	a.events = append(a.events, AnimationEventMetadata{Time: time, Name: name, Data: data})
+
+	sort.SliceStable(a.events, func(i, j int) bool {
+		return a.events[i].Time < a.events[j].Time
+	})
+}
+
+// NewAnimationClip creates a new empty animation clip.
+func NewAnimationClip(name string) *AnimationClip {
+	a := &AnimationClip{}
+
+	a.SetName(name)
+	GetInstance().MustAssign(a)
+
+	return a
+}
+
+// MarshalJSON encodes the interpolation as its name.
+func (i Interpolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON decodes the interpolation from its name.
+func (i *Interpolation) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	switch name {
+	case "", "linear":
+		*i = InterpolationLinear
+	case "step":
+		*i = InterpolationStep
+	case "cubic":
+		*i = InterpolationCubic
+	default:
+		return Error("unknown interpolation " + name)
+	}
+
+	return nil
+}
+
+func (i Interpolation) String() string {
+	switch i {
+	case InterpolationStep:
+		return "step"
+	case InterpolationCubic:
+		return "cubic"
+	default:
+		return "linear"
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+type animTarget struct {
+	BaseScriptComponent
+
+	value float32
+}
+
+func init() {
+	RegisterComponentType("animTarget", &animTarget{}, nil)
+	RegisterAnimationProperty("animTarget", "value", &AnimationProperty{
+		Size: 1,
+		Get: func(c Component) []float32 {
+			return []float32{c.(*animTarget).value}
+		},
+		Set: func(c Component, v []float32) {
+			c.(*animTarget).value = v[0]
+		},
+	})
+}
+
+func newAnimTarget(value float32) *animTarget {
+	c := &animTarget{value: value}
+	c.SetName("animTarget")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func newAnimClip(t *testing.T, name, path string, from, to float32) *AnimationClip {
+	clip := NewAnimationClip(name)
+	if _, err := clip.AddCurve(path, InterpolationLinear,
+		Keyframe{Time: 0, Value: []float32{from}},
+		Keyframe{Time: 1, Value: []float32{to}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return clip
+}
+
+func TestParseAnimationPath(t *testing.T) {
+	object, component, property, err := ParseAnimationPath("arm/hand:Transform.rotation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object != "arm/hand" || component != "Transform" || property != "rotation" {
+		t.Errorf("unexpected split %q %q %q", object, component, property)
+	}
+
+	if object, _, _, err := ParseAnimationPath(":Camera.fov"); err != nil || object != "" {
+		t.Errorf("root path: %q %v", object, err)
+	}
+
+	for _, path := range []string{"Transform.position", "a:Transform", "a:.position", "a:Transform."} {
+		if _, _, _, err := ParseAnimationPath(path); err != ErrAnimationPath {
+			t.Errorf("%q: expected ErrAnimationPath, got %v", path, err)
+		}
+	}
+}
+
+func TestAnimationCurve_Evaluate(t *testing.T) {
+	clip := NewAnimationClip("test")
+	keys := []Keyframe{
+		{Time: 0, Value: []float32{0}},
+		{Time: 1, Value: []float32{10}},
+		{Time: 2, Value: []float32{0}},
+	}
+
+	linear, err := clip.AddCurve(":animTarget.value", InterpolationLinear, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, err := clip.AddCurve("a:animTarget.value", InterpolationStep, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cubic, err := clip.AddCurve("b:animTarget.value", InterpolationCubic, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clip.Length() != 2 {
+		t.Errorf("length %v, expected 2", clip.Length())
+	}
+
+	out := make([]float32, 1)
+	for _, c := range []struct {
+		curve *AnimationCurve
+		t     float64
+		v     float32
+	}{
+		{linear, -1, 0}, {linear, 0.5, 5}, {linear, 1.5, 5}, {linear, 3, 0},
+		{step, 0.5, 0}, {step, 1.5, 10},
+		{cubic, 0, 0}, {cubic, 1, 10}, {cubic, 2, 0},
+	} {
+		c.curve.Evaluate(c.t, out)
+		if !approx(float64(out[0]), float64(c.v)) {
+			t.Errorf("%s %v: %v, expected %v", c.curve.Interpolation, c.t, out[0], c.v)
+		}
+	}
+
+	// The Catmull-Rom tangent at the peak is flat, so the cubic curve
+	// overshoots the linear one on the way up.
+	cubic.Evaluate(0.75, out)
+	if out[0] <= 7.5 {
+		t.Errorf("cubic at 0.75: %v, expected more than 7.5", out[0])
+	}
+
+	if _, err := clip.AddCurve("c:animTarget.value", InterpolationLinear,
+		Keyframe{Time: 1, Value: []float32{0}},
+		Keyframe{Time: 0, Value: []float32{0}},
+	); err != ErrAnimationKeyOrder {
+		t.Errorf("expected ErrAnimationKeyOrder, got %v", err)
+	}
+	if _, err := clip.AddCurve("c:animTarget.value", InterpolationLinear); err != ErrAnimationNoKeys {
+		t.Errorf("expected ErrAnimationNoKeys, got %v", err)
+	}
+}
+
+func TestDecodeAnimationClip(t *testing.T) {
+	data := `{
+		"name": "bob",
+		"loop": true,
+		"curves": [{
+			"path": ":Transform.position",
+			"interpolation": "step",
+			"keys": [{"time": 0, "value": [0, 0, 0]}, {"time": 0.5, "value": [0, 1, 0]}]
+		}],
+		"events": [{"time": 0.25, "name": "step"}]
+	}`
+
+	m, err := DecodeAnimationClip(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clip, err := NewAnimationClipFromMetadata(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clip.Name() != "bob" || !clip.Loop() || clip.Length() != 0.5 {
+		t.Errorf("unexpected clip %q loop=%v length=%v", clip.Name(), clip.Loop(), clip.Length())
+	}
+	if len(clip.Curves()) != 1 || clip.Curves()[0].Interpolation != InterpolationStep {
+		t.Fatalf("unexpected curves %+v", clip.Curves())
+	}
+	if len(clip.Events()) != 1 || clip.Events()[0].Name != "step" {
+		t.Errorf("unexpected events %+v", clip.Events())
+	}
+
+	if out := clip.Metadata(); out.Name != m.Name || len(out.Curves) != 1 || len(out.Events) != 1 {
+		t.Errorf("metadata did not round trip: %+v", out)
+	}
+}
+
+func TestAnimator_Play(t *testing.T) {
+	setupTestApp(t)
+
+	root := NewGameObject("root")
+	child := NewGameObject("child")
+	root.AddChild(child)
+	child.SetParent(root)
+
+	target := newAnimTarget(0)
+	child.AddComponent(target)
+
+	animator := NewAnimator()
+	root.AddComponent(animator)
+	animator.AddClip(newAnimClip(t, "rise", "child:animTarget.value", 0, 10))
+
+	if err := animator.Play("missing"); err == nil {
+		t.Fatal("expected error playing missing clip")
+	}
+	if err := animator.Play("rise"); err != nil {
+		t.Fatal(err)
+	}
+
+	animator.Step(0.5)
+	if !approx(float64(target.value), 5) {
+		t.Errorf("value %v, expected 5", target.value)
+	}
+
+	animator.Step(1)
+	if !approx(float64(target.value), 10) || animator.Time("rise") != 1 {
+		t.Errorf("value %v at %v, expected 10 at 1", target.value, animator.Time("rise"))
+	}
+
+	animator.SetSpeed(-1)
+	animator.Step(0.25)
+	if !approx(float64(target.value), 7.5) {
+		t.Errorf("value %v, expected 7.5", target.value)
+	}
+}
+
+func TestAnimator_CrossFade(t *testing.T) {
+	setupTestApp(t)
+
+	g := NewGameObject("g")
+	target := newAnimTarget(100)
+	g.AddComponent(target)
+
+	animator := NewAnimator()
+	g.AddComponent(animator)
+	animator.AddClip(newAnimClip(t, "low", ":animTarget.value", 0, 0))
+	animator.AddClip(newAnimClip(t, "high", ":animTarget.value", 10, 10))
+
+	if err := animator.Play("low"); err != nil {
+		t.Fatal(err)
+	}
+	animator.Step(0.1)
+	if target.value != 0 {
+		t.Fatalf("value %v, expected 0", target.value)
+	}
+
+	if err := animator.CrossFade("high", 1); err != nil {
+		t.Fatal(err)
+	}
+	animator.Step(0.5)
+	if !approx(float64(target.value), 5) {
+		t.Errorf("value %v halfway through cross fade, expected 5", target.value)
+	}
+
+	animator.Step(0.5)
+	if animator.IsPlaying("low") || !animator.IsPlaying("high") {
+		t.Errorf("low playing %v, high playing %v", animator.IsPlaying("low"), animator.IsPlaying("high"))
+	}
+	if !approx(float64(target.value), 10) {
+		t.Errorf("value %v after cross fade, expected 10", target.value)
+	}
+
+	// A partial weight blends with the value before animation.
+	animator.StopAll()
+	if err := animator.Blend("high", 0.5, 0); err != nil {
+		t.Fatal(err)
+	}
+	animator.Step(0)
+	if !approx(float64(target.value), 55) {
+		t.Errorf("value %v with half weight, expected 55", target.value)
+	}
+}
+
+func TestAnimator_Events(t *testing.T) {
+	setupTestApp(t)
+
+	g := NewGameObject("g")
+	g.AddComponent(newAnimTarget(0))
+
+	animator := NewAnimator()
+	g.AddComponent(animator)
+
+	clip := newAnimClip(t, "walk", ":animTarget.value", 0, 1)
+	clip.SetLoop(true)
+	clip.AddEvent(0, "start", "")
+	clip.AddEvent(0.5, "step", "left")
+	animator.AddClip(clip)
+
+	fired := []string{}
+	animator.OnEvent(func(e AnimationEvent) {
+		fired = append(fired, e.Name)
+	})
+
+	published := 0
+	Subscribe(g.Events(), nil, func(e AnimationEvent) {
+		published++
+	})
+
+	if err := animator.Play("walk"); err != nil {
+		t.Fatal(err)
+	}
+
+	animator.Step(0.25)
+	animator.Step(0.5)
+	animator.Step(0.5)
+
+	expected := []string{"start", "step", "start"}
+	if strings.Join(fired, ",") != strings.Join(expected, ",") {
+		t.Errorf("fired %v, expected %v", fired, expected)
+	}
+	if published != len(fired) {
+		t.Errorf("published %d events, expected %d", published, len(fired))
+	}
+	if !approx(animator.Time("walk"), 0.25) {
+		t.Errorf("time %v, expected 0.25", animator.Time("walk"))
+	}
+}
+
+func TestAnimator_Clone(t *testing.T) {
+	setupTestApp(t)
+
+	animator := NewAnimator()
+	animator.SetSpeed(2)
+	animator.SetAutoplay("idle")
+	animator.AddClip(NewAnimationClip("idle"))
+
+	c, err := animator.CloneComponent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := c.(*Animator)
+	if clone.ID() == animator.ID() || clone.Speed() != 2 || clone.autoplay != "idle" || len(clone.Clips()) != 1 {
+		t.Fatalf("animator not copied: %+v", clone)
+	}
+
+	// The copy has its own clip set.
+	clone.AddClip(NewAnimationClip("walk"))
+	if len(animator.Clips()) != 1 {
+		t.Errorf("original clips changed: %v", animator.Clips())
+	}
+}
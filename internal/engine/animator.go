@@ -0,0 +1,611 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ ScriptComponent = &Animator{}
+
+type ErrClipNotFound string
+
+func (e ErrClipNotFound) Error() string {
+	return "animation clip " + string(e) + " not found"
+}
+
+// AnimationEvent is published on the local event bus of an Animator's
+// GameObject when playback passes the time of a clip event.
+type AnimationEvent struct {
+	Animator *Animator
+	Clip     *AnimationClip
+	Time     float64
+	Name     string
+	Data     string
+}
+
+type animatorProperties struct {
+	Clips []string `json:"clips,omitempty"`
+	Play  string   `json:"play,omitempty"`
+	Speed float64  `json:"speed"`
+}
+
+// animationTarget is a component property animated by one or more clips.
+type animationTarget struct {
+	component Component
+	property  *AnimationProperty
+	rest      []float32
+	value     []float32
+	sample    []float32
+	weight    float32
+}
+
+type animationBinding struct {
+	curve  *AnimationCurve
+	target *animationTarget
+}
+
+// animationState is a clip being played by an Animator.
+type animationState struct {
+	clip     *AnimationClip
+	bindings []animationBinding
+	time     float64
+	weight   float64
+	target   float64
+	fade     float64
+	started  bool
+}
+
+// Animator plays animation clips on its GameObject and its descendants. Clips
+// played at the same time are blended by weight. Where the total weight is
+// below one, the values the properties had when first animated are blended in.
+type Animator struct {
+	BaseScriptComponent
+
+	clips     map[string]*AnimationClip
+	clipNames []string
+	states    []*animationState
+	targets   map[string]*animationTarget
+	onEvent   func(AnimationEvent)
+	autoplay  string
+	speed     float64
+}
+
+func init() {
+	RegisterComponentType("Animator", &Animator{}, decodeAnimator)
+}
+
+// NewAnimator creates a new Animator.
+func NewAnimator() *Animator {
+	c := &Animator{
+		clips:   make(map[string]*AnimationClip),
+		targets: make(map[string]*animationTarget),
+		speed:   1,
+	}
+
+	c.SetName("Animator")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func AnimatorComponent(g *GameObject) *Animator {
+	return GetComponent[*Animator](g)
+}
+
+// AddClip adds a clip which can then be played by its name.
+func (c *Animator) AddClip(clip *AnimationClip) {
+	c.clips[clip.Name()] = clip
+}
+
+// Clip returns the clip with the given name, or nil.
+func (c *Animator) Clip(name string) *AnimationClip {
+	return c.clips[name]
+}
+
+// Clips returns the names of the clips of the Animator in sorted order.
+func (c *Animator) Clips() []string {
+	names := make([]string, 0, len(c.clips))
+	for name := range c.clips {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Speed returns the playback speed.
+func (c *Animator) Speed() float64 {
+	return c.speed
+}
+
+// SetSpeed sets the playback speed.
+func (c *Animator) SetSpeed(speed float64) {
+	c.speed = speed
+}
+
+// OnEvent sets a function called for every animation event, in addition to
+// the event being published on the GameObject's event bus.
+func (c *Animator) OnEvent(fn func(AnimationEvent)) {
+	c.onEvent = fn
+}
+
+// Play plays the clip from the start at full weight, stopping all others.
+func (c *Animator) Play(name string) error {
+	s, err := c.state(name)
+	if err != nil {
+		return err
+	}
+
+	c.states = c.states[:0]
+	c.states = append(c.states, s)
+
+	s.weight, s.target = 1, 1
+
+	return nil
+}
+
+// CrossFade fades the clip in from the start over the given duration, while
+// fading out all other clips.
+func (c *Animator) CrossFade(name string, duration float64) error {
+	s, err := c.state(name)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.states {
+		c.states[i].fadeTo(0, duration)
+	}
+
+	c.states = append(c.states, s)
+	s.fadeTo(1, duration)
+
+	return nil
+}
+
+// Blend fades the weight of the clip to the given weight over the given
+// duration, starting the clip if it is not playing. Other clips are not
+// affected.
+func (c *Animator) Blend(name string, weight, duration float64) error {
+	if s := c.playing(name); s != nil {
+		s.fadeTo(weight, duration)
+		return nil
+	}
+
+	s, err := c.state(name)
+	if err != nil {
+		return err
+	}
+
+	c.states = append(c.states, s)
+	s.fadeTo(weight, duration)
+
+	return nil
+}
+
+// Stop stops the clip with the given name.
+func (c *Animator) Stop(name string) {
+	states := c.states[:0]
+	for _, s := range c.states {
+		if s.clip.Name() != name {
+			states = append(states, s)
+		}
+	}
+
+	c.states = states
+}
+
+// StopAll stops all clips.
+func (c *Animator) StopAll() {
+	c.states = c.states[:0]
+}
+
+// IsPlaying reports if the clip with the given name is playing.
+func (c *Animator) IsPlaying(name string) bool {
+	return c.playing(name) != nil
+}
+
+// Weight returns the current weight of the clip, or zero if it is not
+// playing.
+func (c *Animator) Weight(name string) float64 {
+	if s := c.playing(name); s != nil {
+		return s.weight
+	}
+
+	return 0
+}
+
+// Time returns the playback time of the clip, or zero if it is not playing.
+func (c *Animator) Time(name string) float64 {
+	if s := c.playing(name); s != nil {
+		return s.time
+	}
+
+	return 0
+}
+
+// Rebind resolves the paths of all clips again. It must be called after the
+// animated hierarchy changes.
+func (c *Animator) Rebind() {
+	c.targets = make(map[string]*animationTarget)
+
+	for _, s := range c.states {
+		s.bindings = c.bind(s.clip)
+	}
+}
+
+// Step advances playback by delta seconds, fires events and applies the
+// animated values. It is called every frame by Update.
+func (c *Animator) Step(delta float64) {
+	delta *= c.speed
+
+	states := c.states[:0]
+	for _, s := range c.states {
+		c.advance(s, delta)
+
+		if s.weight > 0 || s.target > 0 {
+			states = append(states, s)
+		}
+	}
+
+	for i := len(states); i < len(c.states); i++ {
+		c.states[i] = nil
+	}
+	c.states = states
+
+	c.apply()
+}
+
+func (c *Animator) Update() {
+	g := c.GameObject()
+	if g == nil || g.App() == nil {
+		return
+	}
+
+	c.Step(g.App().Time().DeltaTime())
+}
+
+// Awake loads the clips named in the component properties and starts the
+// default clip.
+func (c *Animator) Awake() {
+	if g := c.GameObject(); len(c.clipNames) != 0 && g != nil && g.App() != nil {
+		c.loadClips(g.App())
+	}
+
+	if c.autoplay != "" {
+		if err := c.Play(c.autoplay); err != nil {
+			logrus.Error("animator: ", err)
+		}
+	}
+}
+
+// loadClips adds the clips named in the component properties from the
+// animation assets of the App.
+func (c *Animator) loadClips(a *App) {
+	names := c.clipNames
+	c.clipNames = nil
+
+	h, err := handlerOf[*AnimationHandler](a.Asset(), AssetNameAnimation)
+	if err != nil {
+		logrus.Error("animator: ", err)
+		return
+	}
+
+	for _, name := range names {
+		clip, err := h.Get(name)
+		if err != nil {
+			logrus.Error("animator: ", err)
+			continue
+		}
+		c.AddClip(clip)
+	}
+}
+
+// CloneComponent returns a copy of the Animator. Clips are shared with the
+// original, playback state is not copied.
+func (c *Animator) CloneComponent() (Component, error) {
+	n := NewAnimator()
+	for name, clip := range c.clips {
+		n.clips[name] = clip
+	}
+	n.clipNames = append(n.clipNames, c.clipNames...)
+	n.onEvent = c.onEvent
+	n.autoplay = c.autoplay
+	n.speed = c.speed
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the Animator.
+func (c *Animator) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&animatorProperties{
+		Clips: append(c.Clips(), c.clipNames...),
+		Play:  c.autoplay,
+		Speed: c.speed,
+	})
+}
+
+// SetAutoplay sets the clip played when the Animator awakes.
+func (c *Animator) SetAutoplay(name string) {
+	c.autoplay = name
+}
+
+func (c *Animator) playing(name string) *animationState {
+	for _, s := range c.states {
+		if s.clip.Name() == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func (c *Animator) state(name string) (*animationState, error) {
+	clip, ok := c.clips[name]
+	if !ok {
+		return nil, ErrClipNotFound(name)
+	}
+
+	c.Stop(name)
+
+	return &animationState{clip: clip, bindings: c.bind(clip)}, nil
+}
+
+// bind resolves the curves of the clip to component properties.
+func (c *Animator) bind(clip *AnimationClip) []animationBinding {
+	bindings := []animationBinding{}
+
+	for _, curve := range clip.Curves() {
+		t, ok := c.targets[curve.Path]
+		if !ok {
+			t = c.resolve(curve)
+			if t == nil {
+				continue
+			}
+			c.targets[curve.Path] = t
+		}
+
+		if curve.Size() != t.property.Size {
+			logrus.Warnf("animator: %s: curve has %d values, property has %d", curve.Path, curve.Size(), t.property.Size)
+			continue
+		}
+
+		bindings = append(bindings, animationBinding{curve: curve, target: t})
+	}
+
+	return bindings
+}
+
+func (c *Animator) resolve(curve *AnimationCurve) *animationTarget {
+	g := c.GameObject()
+	if g == nil {
+		return nil
+	}
+	if curve.ObjectPath() != "" {
+		if g = g.Find(curve.ObjectPath()); g == nil {
+			logrus.Warnf("animator: %s: object not found", curve.Path)
+			return nil
+		}
+	}
+
+	property, ok := FindAnimationProperty(curve.Component(), curve.Property())
+	if !ok {
+		logrus.Warnf("animator: %s: property is not animatable", curve.Path)
+		return nil
+	}
+
+	var component Component
+	if curve.Component() == "Transform" {
+		component = g.Transform()
+	} else {
+		for _, gc := range g.Components() {
+			if name, ok := ComponentTypeName(gc); ok && name == curve.Component() {
+				component = gc
+				break
+			}
+		}
+	}
+	if component == nil {
+		logrus.Warnf("animator: %s: component not found", curve.Path)
+		return nil
+	}
+
+	return &animationTarget{
+		component: component,
+		property:  property,
+		rest:      append([]float32(nil), property.Get(component)...),
+		value:     make([]float32, property.Size),
+		sample:    make([]float32, property.Size),
+	}
+}
+
+// advance moves the state forward in time, fires the events passed and fades
+// its weight.
+func (c *Animator) advance(s *animationState, delta float64) {
+	prev := s.time
+	s.time += delta
+
+	length := s.clip.Length()
+	if s.clip.Loop() && length > 0 {
+		wraps := math.Floor(s.time / length)
+		s.time -= wraps * length
+
+		if s.weight > 0 || s.target > 0 {
+			if wraps > 0 {
+				c.fireEvents(s, prev, length, !s.started)
+				c.fireEvents(s, 0, s.time, true)
+			} else {
+				c.fireEvents(s, prev, s.time, !s.started)
+			}
+		}
+	} else {
+		s.time = math.Min(s.time, length)
+
+		if s.weight > 0 || s.target > 0 {
+			c.fireEvents(s, prev, s.time, !s.started)
+		}
+	}
+	s.started = true
+
+	if s.weight < s.target {
+		s.weight = math.Min(s.weight+s.fade*delta, s.target)
+	} else if s.weight > s.target {
+		s.weight = math.Max(s.weight-s.fade*delta, s.target)
+	}
+}
+
+// fireEvents fires the events in the time range (from, to], or [from, to] if
+// inclusive is true.
+func (c *Animator) fireEvents(s *animationState, from, to float64, inclusive bool) {
+	for _, e := range s.clip.Events() {
+		if e.Time < from || e.Time > to || (e.Time == from && !inclusive) {
+			continue
+		}
+
+		event := AnimationEvent{
+			Animator: c,
+			Clip:     s.clip,
+			Time:     e.Time,
+			Name:     e.Name,
+			Data:     e.Data,
+		}
+
+		if g := c.GameObject(); g != nil {
+			Publish(g.Events(), event)
+		}
+		if c.onEvent != nil {
+			c.onEvent(event)
+		}
+	}
+}
+
+// apply blends the values of all playing clips and sets the properties.
+func (c *Animator) apply() {
+	for _, t := range c.targets {
+		t.weight = 0
+		for i := range t.value {
+			t.value[i] = 0
+		}
+	}
+
+	for _, s := range c.states {
+		if s.weight <= 0 {
+			continue
+		}
+
+		w := float32(s.weight)
+		for _, b := range s.bindings {
+			t := b.target
+			b.curve.Evaluate(s.time, t.sample)
+			t.accumulate(t.sample, w)
+		}
+	}
+
+	for _, t := range c.targets {
+		if t.weight <= 0 {
+			continue
+		}
+		if t.weight < 1 {
+			t.accumulate(t.rest, 1-t.weight)
+		}
+
+		for i := range t.value {
+			t.value[i] /= t.weight
+		}
+		if t.property.Rotation {
+			normalize(t.value)
+		}
+
+		t.property.Set(t.component, t.value)
+	}
+}
+
+// accumulate adds the weighted value. Rotations are flipped into the same
+// hemisphere as the values accumulated so far.
+func (t *animationTarget) accumulate(value []float32, weight float32) {
+	if t.property.Rotation && t.weight > 0 {
+		var dot float32
+		for i := range value {
+			dot += value[i] * t.value[i]
+		}
+		if dot < 0 {
+			weight = -weight
+		}
+	}
+
+	for i := range value {
+		t.value[i] += value[i] * weight
+	}
+	if weight < 0 {
+		weight = -weight
+	}
+	t.weight += weight
+}
+
+func (s *animationState) fadeTo(weight, duration float64) {
+	s.target = weight
+
+	if duration <= 0 {
+		s.weight = weight
+		return
+	}
+
+	s.fade = math.Abs(weight-s.weight) / duration
+}
+
+func normalize(v []float32) {
+	var sum float32
+	for i := range v {
+		sum += v[i] * v[i]
+	}
+	if sum == 0 {
+		return
+	}
+
+	l := float32(math.Sqrt(float64(sum)))
+	for i := range v {
+		v[i] /= l
+	}
+}
+
+func decodeAnimator(properties []byte) (Component, error) {
+	p := &animatorProperties{Speed: 1}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	c := NewAnimator()
+	c.clipNames = p.Clips
+	c.autoplay = p.Play
+	c.speed = p.Speed
+
+	return c, nil
+}
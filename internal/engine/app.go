@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"syscall"
 
@@ -34,20 +35,42 @@ import (
 )
 
 const (
-	fixedTime    = float64(0.05)
-	maxFrameSkip = 5
-
 	builtinAssets = "<builtin>:builtin.json"
 )
 
 var (
-	app     *App
-	appOnce sync.Once
+	currentApp   *App
+	currentAppMu sync.RWMutex
 )
 
 // AppConfig provides options for configuring an App.
 type AppConfig struct {
 	Name string
+
+	// Headless runs the App without a window or OpenGL context. Input is
+	// never reported, rendering is skipped and GL-backed asset handlers are
+	// not registered.
+	Headless bool
+
+	// MaxFrames stops the App after the given number of frames. Zero runs
+	// until the App quits.
+	MaxFrames int
+
+	// Clock is the time source of the App. Defaults to a RealClock.
+	Clock Clock
+
+	// FixedStep is the duration of a fixed update tick in seconds. Defaults
+	// to DefaultFixedStep.
+	FixedStep float64
+
+	// MaxCatchUp is the maximum number of fixed update ticks run per frame.
+	// Defaults to DefaultMaxCatchUp.
+	MaxCatchUp int
+
+	// MainThreadBudget is the maximum number of functions scheduled with
+	// RunOnMain, such as GL uploads of asynchronous scene loads, run per
+	// frame. Defaults to DefaultMainThreadBudget.
+	MainThreadBudget int
 }
 
 // App is the backbone of any Apex application.
@@ -55,38 +78,76 @@ type App struct {
 	scenes           map[string]*Scene
 	systems          []System
 	activeScenes     []string
+	mainTasks        []func()
+	mainMu           sync.Mutex
+	sceneSwitch      *sceneSwitch
+	clock            Clock
 	preStartFunc     func() error
 	postStartFunc    func() error
 	preTeardownFunc  func()
 	postTeardownFunc func()
 	name             string
+	fixedStep        float64
+	maxCatchUp       int
+	mainBudget       int
+	maxFrames        int
+	headless         bool
 	running          bool
 }
 
 // NewApp creates a new App using the provided AppConfig for customization.
 func NewApp(cfg *AppConfig) *App {
 	a := &App{
-		name: cfg.Name,
+		name:       cfg.Name,
+		headless:   cfg.Headless,
+		maxFrames:  cfg.MaxFrames,
+		clock:      cfg.Clock,
+		fixedStep:  cfg.FixedStep,
+		maxCatchUp: cfg.MaxCatchUp,
+		mainBudget: cfg.MainThreadBudget,
+	}
+
+	if a.mainBudget <= 0 {
+		a.mainBudget = DefaultMainThreadBudget
 	}
 
 	return a
 }
 
-// CurrentApp returns the current app.
+// CurrentApp returns the current App, or nil if there is none. The package
+// level accessors such as GetInstance and GetAsset, and the facades in
+// engine/system, resolve against the current App. Code with a scene or
+// GameObject at hand should prefer their App method.
 func CurrentApp() *App {
-	return app
+	currentAppMu.RLock()
+	defer currentAppMu.RUnlock()
+
+	return currentApp
 }
 
-// SetApp sets the App, but only once.
-func setApp(a *App) {
-	appOnce.Do(func() {
-		app = a
-	})
+// MakeCurrent makes this App the current App. An App is made current when it
+// is set up and when it starts running, so several Apps can coexist in one
+// process as long as only one runs at a time.
+func (a *App) MakeCurrent() {
+	currentAppMu.Lock()
+	defer currentAppMu.Unlock()
+
+	currentApp = a
+}
+
+// releaseCurrent clears the current App if it is this App.
+func (a *App) releaseCurrent() {
+	currentAppMu.Lock()
+	defer currentAppMu.Unlock()
+
+	if currentApp == a {
+		currentApp = nil
+	}
 }
 
 // Setup sets up the App.
 func (a *App) Setup() error {
-	setApp(a)
+	a.MakeCurrent()
 
 	LoadGlobalConfig()
 
@@ -95,17 +156,32 @@ func (a *App) Setup() error {
 	a.activeScenes = []string{}
 
 	// Register required systems.
-	a.RegisterSystem(NewWindow())
+	if a.headless {
+		a.RegisterSystem(NewNullWindow())
+	} else {
+		a.RegisterSystem(NewWindow())
+	}
 	a.RegisterSystem(NewInstance())
 	a.RegisterSystem(NewAsset())
-	a.RegisterSystem(NewTime())
 
-	// Register asset handlers.
-	asset := GetAsset()
-	asset.RegisterHandler(NewImageHandler())
-	asset.RegisterHandler(NewMeshHandler())
-	asset.RegisterHandler(NewShaderHandler())
-	asset.RegisterHandler(NewSkyboxHandler())
+	time := NewTime(a.clock)
+	time.SetFixedStep(a.fixedStep)
+	time.SetMaxCatchUp(a.maxCatchUp)
+	a.RegisterSystem(time)
+	a.RegisterSystem(NewProfiler(!a.headless))
+
+	// Register asset handlers. Handlers which upload to the GPU are not
+	// available without an OpenGL context.
+	asset := a.Asset()
+	if !a.headless {
+		asset.RegisterHandler(NewImageHandler())
+		asset.RegisterHandler(NewMeshHandler())
+		asset.RegisterHandler(NewShaderHandler())
+		asset.RegisterHandler(NewSkyboxHandler())
+	}
+	asset.RegisterHandler(NewSceneHandler())
+	asset.RegisterHandler(NewPrefabHandler())
+	asset.RegisterHandler(NewAnimationHandler())
 
 	if a.preStartFunc != nil {
 		if err := a.preStartFunc(); err != nil {
@@ -113,6 +189,12 @@ func (a *App) Setup() error {
 		}
 	}
 
+	systems, err := sortSystems(a.systems)
+	if err != nil {
+		return err
+	}
+	a.systems = systems
+
 	for i := range a.systems {
 		logrus.Debug("Setting up system: ", a.systems[i].Name())
 
@@ -122,8 +204,10 @@ func (a *App) Setup() error {
 	}
 
 	// Load base assets.
-	if err := asset.LoadManifest(builtinAssets); err != nil {
-		return err
+	if !a.headless {
+		if err := asset.LoadManifest(builtinAssets); err != nil {
+			return err
+		}
 	}
 
 	if a.postStartFunc != nil {
@@ -147,49 +231,118 @@ func (a *App) Teardown() {
 		a.systems[i].Teardown()
 	}
 
+	for _, s := range a.scenes {
+		s.Scheduler().CancelAll()
+	}
+
 	if a.postTeardownFunc != nil {
 		a.postTeardownFunc()
 	}
+
+	a.releaseCurrent()
 }
 
 // Run starts the main loop of the app.
 func (a *App) Run() error {
+	return a.run(0)
+}
+
+// RunTicks runs the main loop until exactly n fixed update ticks have run.
+// Each frame advances game time by one fixed step regardless of the clock, so
+// runs with the same input are deterministic.
+func (a *App) RunTicks(n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	return a.run(n)
+}
+
+func (a *App) run(ticks int) error {
+	a.MakeCurrent()
 	a.running = true
 
-	a.setupSignalHandler()
+	defer a.setupSignalHandler()()
 
 	frame := 0
 	loops := 0
 
-	time := a.MustSystem(SysNameTime).(*Time)
-	window := a.MustSystem(SysNameWindow).(*Window)
+	time := a.Time()
+	window := a.Window()
+	profiler := a.Profiler()
+
+	start := time.Ticks()
 
 	for a.running {
 		a.running = !window.ShouldClose()
 
-		time.FrameStart()
+		if ticks > 0 {
+			time.FrameStep()
+		} else {
+			time.FrameStart()
+		}
 
 		frame++
 
+		profiler.BeginFrame()
+		frameScope := profiler.Begin("frame")
+
 		if window.KeyDown(glfw.KeyF9) {
 			a.debugInfo()
 		}
 
+		a.runMainTasks()
+		a.updateSceneSwitch(time.UnscaledDelta())
+
+		scope := profiler.Begin("update")
+		a.onSystemPreUpdate()
 		a.onUpdate()
+		a.onSystemUpdate()
+		scope.End()
 
 		loops = 0
-		for time.LogicUpdate() && loops < maxFrameSkip {
+		for time.LogicUpdate() && loops < time.MaxCatchUp() {
+			scope := profiler.Begin("fixed_update")
 			time.LogicTick()
 			a.onFixedUpdate()
+			a.onSystemFixedUpdate()
+			scope.End()
 			loops++
 		}
+		if loops == time.MaxCatchUp() {
+			time.dropBacklog()
+		}
 
-		window.ClearBuffers()
-		a.onDisplay()
-		window.SwapBuffers()
+		time.Interpolate()
+		a.onInterpolate(float32(time.InterpTime()))
+
+		scope = profiler.BeginGPU("render")
+		if !a.headless {
+			window.ClearBuffers()
+			a.onDisplay()
+		}
+		a.onSystemPostRender()
+		scope.End()
+
+		if !a.headless {
+			window.SwapBuffers()
+		}
+
+		// Apply structural changes queued during this frame.
+		a.onFrameEnd()
 
 		window.HandleEvents()
 		time.FrameEnd()
+
+		frameScope.End()
+		profiler.EndFrame()
+
+		if a.maxFrames > 0 && frame >= a.maxFrames {
+			a.running = false
+		}
+		if ticks > 0 && time.Ticks()-start >= uint64(ticks) {
+			a.running = false
+		}
 	}
 
 	return nil
@@ -200,13 +353,25 @@ func (a *App) Quit() {
 	a.running = false
 }
 
+// Headless reports if the App runs without a window or OpenGL context.
+func (a *App) Headless() bool {
+	return a.headless
+}
+
+// glAvailable reports if the current App has an OpenGL context.
+func glAvailable() bool {
+	a := CurrentApp()
+	return a != nil && !a.Headless()
+}
+
 func (a *App) Name() string {
 	return a.name
 }
 
 // RegisterSystem registers a system with the App. A system can only be added
 // once, it is an error to add a system more than once. Systems are initialized
-// in the order they are added and torn down in the reverse order.
+// after the systems they depend on, otherwise in the order they are added, and
+// torn down in the reverse order.
 func (a *App) RegisterSystem(s System) {
 	// Check for existing system.
 	if a.SystemRegistered(s.Name()) {
@@ -278,6 +443,7 @@ func (a *App) RegisterScene(scene *Scene) error {
 	}
 
 	a.scenes[scene.Name()] = scene
+	scene.app = a
 
 	logrus.Debug("Registered new scene: ", scene.Name())
 
@@ -348,6 +514,7 @@ func (a *App) PopScene() string {
 
 func (a *App) RemoveAllScenes() {
 	for key := range a.scenes {
+		a.scenes[key].Scheduler().CancelAll()
 		delete(a.scenes, key)
 	}
 	a.activeScenes = a.activeScenes[:0]
@@ -358,6 +525,8 @@ func (a *App) UnregisterScene(name string) error {
 		return fmt.Errorf("unregister scene: '%s' not registered", name)
 	}
 
+	a.scenes[name].Scheduler().CancelAll()
+	a.scenes[name].app = nil
 	delete(a.scenes, name)
 
 	return nil
@@ -378,6 +547,30 @@ func (a *App) ActiveScene() *Scene {
 	return nil
 }
 
+// Scene returns the registered scene with the given name, or nil if there is
+// none.
+func (a *App) Scene(name string) *Scene {
+	return a.scenes[name]
+}
+
+// SceneNames returns the names of all registered scenes in sorted order.
+func (a *App) SceneNames() []string {
+	names := make([]string, 0, len(a.scenes))
+	for name := range a.scenes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ActiveSceneNames returns the names of the active scenes, from the bottom to
+// the top of the scene stack.
+func (a *App) ActiveSceneNames() []string {
+	return append([]string{}, a.activeScenes...)
+}
+
 func (a *App) ActiveSceneName() string {
 	if sc := a.ActiveScene(); sc != nil {
 		return sc.Name()
@@ -394,19 +587,60 @@ func (a *App) ActiveSceneCount() int {
 	return len(a.activeScenes)
 }
 
-func (a *App) setupSignalHandler() {
-	s := make(chan os.Signal)
+// setupSignalHandler quits the app on an interrupt or termination signal
+// until the returned function is called.
+func (a *App) setupSignalHandler() func() {
+	s := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
 	signal.Notify(s, os.Interrupt, syscall.SIGTERM)
-	go handleSignal(s, a)
+	go handleSignal(s, done, a)
+
+	return func() {
+		signal.Stop(s)
+		close(done)
+	}
+}
+
+func handleSignal(s chan os.Signal, done chan struct{}, a *App) {
+	select {
+	case <-s:
+		a.Quit()
+	case <-done:
+	}
+}
+
+// updatedScenes returns the active scenes which receive updates, bottom-up.
+func (a *App) updatedScenes() []*Scene {
+	return a.uncoveredScenes((*Scene).UpdateWhenCovered)
 }
 
-func handleSignal(s chan os.Signal, a *App) {
-	<-s
-	a.Quit()
+// renderedScenes returns the active scenes which are rendered, bottom-up.
+func (a *App) renderedScenes() []*Scene {
+	return a.uncoveredScenes((*Scene).RenderWhenCovered)
 }
 
+// uncoveredScenes returns the top scene and the covered scenes for which
+// include returns true, bottom-up.
+func (a *App) uncoveredScenes(include func(*Scene) bool) []*Scene {
+	scenes := []*Scene{}
+
+	for i := range a.activeScenes {
+		s := a.scenes[a.activeScenes[i]]
+		if i == len(a.activeScenes)-1 || include(s) {
+			scenes = append(scenes, s)
+		}
+	}
+
+	return scenes
+}
+
+// onDisplay renders the scenes bottom-up. The first active camera replaces the
+// window contents, later cameras are composited over it.
 func (a *App) onDisplay() {
-	if s := a.ActiveScene(); s != nil {
+	composite := false
+
+	for _, s := range a.renderedScenes() {
 		sg := s.Graph()
 		if sg.Dirty() {
 			sg.Update()
@@ -414,7 +648,10 @@ func (a *App) onDisplay() {
 
 		cameras := s.cameras
 		for i := range cameras {
-			cameras[i].Render()
+			if cameras[i].Active() {
+				cameras[i].render(composite)
+				composite = true
+			}
 		}
 
 		sg.SendMessage(MessageGUIDisplay)
@@ -422,25 +659,78 @@ func (a *App) onDisplay() {
 }
 
 func (a *App) onUpdate() {
-	if s := a.ActiveScene(); s != nil {
+	time := a.Time()
+
+	for _, s := range a.updatedScenes() {
+		s.Scheduler().beginFrame()
+
 		sg := s.Graph()
 		if sg.Dirty() {
 			sg.Update()
 		}
 
 		sg.SendMessage(MessageUpdate)
+		if !time.Paused() {
+			s.Scheduler().update(time.DeltaTime())
+		}
 		sg.SendMessage(MessageLateUpdate)
 	}
 }
 
 func (a *App) onFixedUpdate() {
-	if s := a.ActiveScene(); s != nil {
+	for _, s := range a.updatedScenes() {
 		sg := s.Graph()
 		if sg.Dirty() {
 			sg.Update()
 		}
 
+		sg.snapshotTransforms()
 		sg.SendMessage(MessageFixedUpdate)
+		s.Scheduler().fixedUpdate()
+	}
+}
+
+func (a *App) onInterpolate(alpha float32) {
+	for _, s := range a.updatedScenes() {
+		s.Graph().interpolateTransforms(alpha)
+	}
+}
+
+func (a *App) onSystemPreUpdate() {
+	for i := range a.systems {
+		if s, ok := a.systems[i].(PreUpdateSystem); ok {
+			s.PreUpdate()
+		}
+	}
+}
+
+func (a *App) onSystemUpdate() {
+	for i := range a.systems {
+		if s, ok := a.systems[i].(UpdateSystem); ok {
+			s.Update()
+		}
+	}
+}
+
+func (a *App) onSystemFixedUpdate() {
+	for i := range a.systems {
+		if s, ok := a.systems[i].(FixedUpdateSystem); ok {
+			s.FixedUpdate()
+		}
+	}
+}
+
+func (a *App) onSystemPostRender() {
+	for i := range a.systems {
+		if s, ok := a.systems[i].(PostRenderSystem); ok {
+			s.PostRender()
+		}
+	}
+}
+
+func (a *App) onFrameEnd() {
+	for i := range a.activeScenes {
+		a.scenes[a.activeScenes[i]].Graph().FlushCommands()
 	}
 }
 
@@ -449,4 +739,12 @@ func (a *App) debugInfo() {
 	fmt.Printf("  App name: %s\n", a.Name())
 	fmt.Printf("  Active scene: %s\n", a.ActiveSceneName())
 	fmt.Printf("  Registered scenes: %d\n", a.SceneCount())
+
+	stats := a.Profiler().Stats()
+	if len(stats) != 0 {
+		fmt.Printf("  Profiler averages over %d frames:\n", DefaultProfileWindow)
+	}
+	for i := range stats {
+		fmt.Printf("    %s %-32s %v\n", stats[i].Category, stats[i].Name, stats[i].Average)
+	}
 }
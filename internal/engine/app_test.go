@@ -0,0 +1,257 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestApp_HeadlessRun(t *testing.T) {
+	a := setupTestApp(t)
+
+	if !a.Headless() {
+		t.Fatal("test app is not headless")
+	}
+
+	script := newTestScript(nil)
+
+	s := NewScene("headless")
+	s.SetLoadFunc(func() error {
+		g := NewGameObject("script")
+		g.AddComponent(script)
+
+		return s.Graph().AddGameObject(g, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("headless"); err != nil {
+		t.Fatal(err)
+	}
+	defer a.PopScene()
+
+	a.maxFrames = 3
+	defer func() { a.maxFrames = 0 }()
+
+	if err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if script.updates != 3 {
+		t.Errorf("updates: %d, expected 3", script.updates)
+	}
+}
+
+func TestApp_RunTicks(t *testing.T) {
+	a := setupTestApp(t)
+
+	script := newTestScript(nil)
+
+	s := NewScene("ticks")
+	s.SetLoadFunc(func() error {
+		g := NewGameObject("script")
+		g.AddComponent(script)
+
+		return s.Graph().AddGameObject(g, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("ticks"); err != nil {
+		t.Fatal(err)
+	}
+	defer a.PopScene()
+
+	if err := a.RunTicks(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if script.fixed != 10 {
+		t.Errorf("fixed updates: %d, expected 10", script.fixed)
+	}
+	if script.updates != 10 {
+		t.Errorf("updates: %d, expected 10", script.updates)
+	}
+}
+
+func TestApp_UpdateWhenCovered(t *testing.T) {
+	a := setupTestApp(t)
+
+	scripts := map[string]*testScript{}
+	for _, name := range []string{"covered-updated", "covered-paused", "covering"} {
+		script := newTestScript(nil)
+		scripts[name] = script
+
+		s := NewScene(name)
+		s.SetLoadFunc(func() error {
+			g := NewGameObject("script")
+			g.AddComponent(script)
+
+			return s.Graph().AddGameObject(g, nil)
+		})
+		s.SetUpdateWhenCovered(name == "covered-updated")
+
+		if err := a.RegisterScene(s); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.PushScene(name); err != nil {
+			t.Fatal(err)
+		}
+		defer a.PopScene()
+	}
+
+	if err := a.RunTicks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := scripts["covering"].updates; n != 2 {
+		t.Errorf("covering scene updates: %d, expected 2", n)
+	}
+	if n := scripts["covered-updated"].updates; n != 2 {
+		t.Errorf("covered scene with UpdateWhenCovered updates: %d, expected 2", n)
+	}
+	if n := scripts["covered-paused"].updates; n != 0 {
+		t.Errorf("covered scene updates: %d, expected 0", n)
+	}
+}
+
+func TestApp_MultipleApps(t *testing.T) {
+	a := setupTestApp(t)
+	b := setupTestApp(t)
+
+	if CurrentApp() != b {
+		t.Fatal("expected the last App set up to be current")
+	}
+	if a.Instance() == b.Instance() {
+		t.Fatal("Apps share an instance system")
+	}
+
+	sa := NewScene("a")
+	if err := a.RegisterScene(sa); err != nil {
+		t.Fatal(err)
+	}
+	if sa.App() != a {
+		t.Error("scene does not report the App it is registered with")
+	}
+
+	a.MakeCurrent()
+	if CurrentApp() != a || GetInstance() != a.Instance() {
+		t.Error("MakeCurrent did not switch the current App")
+	}
+
+	a.Teardown()
+	if CurrentApp() != nil {
+		t.Error("expected no current App after teardown")
+	}
+}
+
+func TestNewTransform_NoApp(t *testing.T) {
+	if a := CurrentApp(); a != nil {
+		a.releaseCurrent()
+		t.Cleanup(a.MakeCurrent)
+	}
+
+	transform := NewTransform()
+	if transform.ID() == 0 {
+		t.Fatal("transform was not assigned an ID")
+	}
+	if _, err := detachedInstance.Get(transform.ID()); err != nil {
+		t.Fatal(err)
+	}
+	detachedInstance.Release(transform.ID())
+}
+
+func TestInstance_OwnerRelease(t *testing.T) {
+	if a := CurrentApp(); a != nil {
+		a.releaseCurrent()
+		t.Cleanup(a.MakeCurrent)
+	}
+
+	detached := NewGameObject("detached")
+
+	a := setupTestApp(t)
+	owned := NewGameObject("owned")
+
+	if detached.ID() == owned.ID() {
+		t.Fatalf("detached and App objects share ID %d", owned.ID())
+	}
+
+	id := detached.ID()
+	destroyGameObjects([]*GameObject{detached})
+
+	if _, err := detachedInstance.Get(id); err == nil {
+		t.Error("detached object was not released from the detached instance")
+	}
+	if _, err := a.Instance().Get(owned.ID()); err != nil {
+		t.Errorf("App object released by destroying a detached object: %v", err)
+	}
+
+	id = owned.ID()
+	destroyGameObjects([]*GameObject{owned})
+
+	if _, err := a.Instance().Get(id); err == nil {
+		t.Error("App object was not released")
+	}
+}
+
+func TestApp_RunStopsSignalHandler(t *testing.T) {
+	a := setupTestApp(t)
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		if err := a.RunTicks(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Handlers exit asynchronously once stopped.
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("goroutines: %d, expected at most %d", n, before)
+	}
+}
+
+func TestNewCamera_Headless(t *testing.T) {
+	setupTestApp(t)
+
+	c := NewCamera(RenderPathDeferred, true)
+
+	g := NewGameObject("camera")
+	g.AddComponent(c)
+
+	c.Awake()
+	c.Resize()
+	c.Render()
+
+	if c.ProjectionMatrix() == (mgl32.Mat4{}) {
+		t.Error("headless camera has no projection matrix")
+	}
+}
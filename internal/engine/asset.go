@@ -65,6 +65,13 @@ func (e ErrHandlerNotFound) Error() string {
 	return "asset: no such handler: " + string(e)
 }
 
+// ErrHandlerType reports that the handler has an invalid underlying type.
+type ErrHandlerType string
+
+func (e ErrHandlerType) Error() string {
+	return "asset: type assertion error for handler: " + string(e)
+}
+
 const SysNameAsset = "asset"
 
 type AssetManifest struct {
@@ -97,9 +104,19 @@ type AssetHandler interface {
 	Count() int
 }
 
+// AssetDecoder is implemented by handlers which split loading into a decode
+// step and an upload step. Decode reads and decodes the resource without
+// OpenGL, so it may be called from a goroutine. It returns the upload step,
+// which allocates the asset and adds it to the handler on the main thread.
+type AssetDecoder interface {
+	Decode(*Resource) (func() error, error)
+}
+
 type BaseAssetHandler struct {
 	Items map[string]uint32
 	Mu    *sync.RWMutex
+
+	instances map[string]*Instance
 }
 
 type Asset struct {
@@ -113,6 +130,12 @@ func (a *Asset) Setup() error {
 	return nil
 }
 
+// Dependencies returns the names of the systems the System depends on. Assets
+// hold instance IDs and GPU resources, which must be released first.
+func (a *Asset) Dependencies() []string {
+	return []string{SysNameWindow, SysNameInstance}
+}
+
 // Teardown tears down the System.
 func (a *Asset) Teardown() {
 	a.ReleaseAll()
@@ -255,7 +278,9 @@ func (a *Asset) ReadResource(r *Resource) error {
 
 		return err
 	case ResourcePackage:
+		a.mu.RLock()
 		p, ok := a.packages[r.container]
+		a.mu.RUnlock()
 		if !ok {
 			return ErrPackageNotMounted(r.container)
 		}
@@ -314,6 +339,23 @@ func (a *Asset) GetHandler(name string) (AssetHandler, error) {
 	return nil, ErrHandlerNotFound(name)
 }
 
+// handlerOf gets the asset handler with the given name as a T.
+func handlerOf[T AssetHandler](a *Asset, name string) (T, error) {
+	var zero T
+
+	h, err := a.GetHandler(name)
+	if err != nil {
+		return zero, err
+	}
+
+	t, ok := h.(T)
+	if !ok {
+		return zero, ErrHandlerType(name)
+	}
+
+	return t, nil
+}
+
 // GetAsset gets an asset by name from a handler by kind.
 func (a *Asset) GetAsset(kind, name string) (Object, error) {
 	a.mu.RLock()
@@ -383,7 +425,12 @@ func (h *BaseAssetHandler) GetAsset(name string) (Object, error) {
 	if id, ok := h.Items[name]; !ok {
 		return nil, ErrAssetNotFound(name)
 	} else {
-		obj, err := GetInstance().Get(id)
+		instance := h.instances[name]
+		if instance == nil {
+			instance = GetInstance()
+		}
+
+		obj, err := instance.Get(id)
 		if err != nil {
 			return nil, err
 		}
@@ -392,6 +439,18 @@ func (h *BaseAssetHandler) GetAsset(name string) (Object, error) {
 	}
 }
 
+// add records the asset under the given name. The asset is resolved through
+// the instance system which assigned its ID, so it is found when another App
+// is current.
+func (h *BaseAssetHandler) add(name string, object Object) {
+	if h.instances == nil {
+		h.instances = make(map[string]*Instance)
+	}
+
+	h.Items[name] = object.ID()
+	h.instances[name] = instanceOf(object)
+}
+
 // MustGetAsset is like GetAsset, but panics if an error occurs.
 func (h *BaseAssetHandler) MustGetAsset(name string) Object {
 	h.Mu.RLock()
@@ -423,7 +482,12 @@ func NewAsset() *Asset {
 
 // GetAsset gets the asset system from the current app.
 func GetAsset() *Asset {
-	return CurrentApp().MustSystem(SysNameAsset).(*Asset)
+	return CurrentApp().Asset()
+}
+
+// Asset returns the asset system of the App.
+func (a *App) Asset() *Asset {
+	return a.MustSystem(SysNameAsset).(*Asset)
 }
 
 func NewAssetManifest() *AssetManifest {
@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+const (
+	AssetNameAnimation = "animation" // Identifier is the type name of this asset.
+)
+
+// AnimationClipMetadata is the file representation of an AnimationClip.
+type AnimationClipMetadata struct {
+	Name   string                   `json:"name"`
+	Length float64                  `json:"length,omitempty"`
+	Loop   bool                     `json:"loop,omitempty"`
+	Curves []AnimationCurveMetadata `json:"curves"`
+	Events []AnimationEventMetadata `json:"events,omitempty"`
+}
+
+// AnimationCurveMetadata is the file representation of an AnimationCurve.
+type AnimationCurveMetadata struct {
+	Path          string        `json:"path"`
+	Interpolation Interpolation `json:"interpolation"`
+	Keys          []Keyframe    `json:"keys"`
+}
+
+type AnimationHandler struct {
+	BaseAssetHandler
+}
+
+var _ AssetHandler = &AnimationHandler{}
+var _ AssetDecoder = &AnimationHandler{}
+
+// Load will load data from the reader.
+func (h *AnimationHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode decodes the clip and returns the step which adds it to the handler.
+func (h *AnimationHandler) Decode(r *Resource) (func() error, error) {
+	metadata, err := DecodeAnimationClip(r.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	clip, err := NewAnimationClipFromMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return h.Add(metadata.Name, clip)
+	}, nil
+}
+
+func (h *AnimationHandler) Add(name string, clip *AnimationClip) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.add(name, clip)
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *AnimationHandler) Get(name string) (*AnimationClip, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*AnimationClip)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *AnimationHandler) MustGet(name string) *AnimationClip {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *AnimationHandler) Name() string {
+	return AssetNameAnimation
+}
+
+func NewAnimationHandler() *AnimationHandler {
+	h := &AnimationHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// DecodeAnimationClip reads JSON animation clip metadata from the reader.
+func DecodeAnimationClip(r io.Reader) (*AnimationClipMetadata, error) {
+	metadata := &AnimationClipMetadata{}
+
+	if err := json.NewDecoder(r).Decode(metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// NewAnimationClipFromMetadata creates an animation clip from metadata. The
+// length defaults to the time of the last keyframe.
+func NewAnimationClipFromMetadata(m *AnimationClipMetadata) (*AnimationClip, error) {
+	clip := &AnimationClip{loop: m.Loop}
+	clip.SetName(m.Name)
+
+	for i := range m.Curves {
+		c := &m.Curves[i]
+		if _, err := clip.AddCurve(c.Path, c.Interpolation, c.Keys...); err != nil {
+			return nil, err
+		}
+	}
+	for i := range m.Events {
+		clip.AddEvent(m.Events[i].Time, m.Events[i].Name, m.Events[i].Data)
+	}
+	if m.Length > 0 {
+		clip.length = m.Length
+	}
+
+	GetInstance().MustAssign(clip)
+
+	return clip, nil
+}
+
+// Metadata returns the file representation of the clip.
+func (a *AnimationClip) Metadata() *AnimationClipMetadata {
+	m := &AnimationClipMetadata{
+		Name:   a.Name(),
+		Length: a.length,
+		Loop:   a.loop,
+		Events: a.events,
+	}
+
+	for _, c := range a.curves {
+		m.Curves = append(m.Curves, AnimationCurveMetadata{
+			Path:          c.Path,
+			Interpolation: c.Interpolation,
+			Keys:          c.Keys,
+		})
+	}
+
+	return m
+}
@@ -41,6 +41,7 @@ const (
 )
 
 var _ AssetHandler = &ImageHandler{}
+var _ AssetDecoder = &ImageHandler{}
 
 type ImageHandler struct {
 	BaseAssetHandler
@@ -48,18 +49,24 @@ type ImageHandler struct {
 
 // Load will load data from the reader.
 func (h *ImageHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode decodes the image and returns the step which allocates the texture.
+func (h *ImageHandler) Decode(r *Resource) (func() error, error) {
 	var texture *Texture2D
 	var img image.Image
 
 	name := r.Base()
 
-	if _, dup := h.Items[r.Base()]; dup {
-		return ErrAssetExists(name)
-	}
-
 	img, _, err := image.Decode(r.Reader())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	x := int32(img.Bounds().Dx())
@@ -115,10 +122,12 @@ func (h *ImageHandler) Load(r *Resource) error {
 		texture.SetTexFormat(TextureFormatRGBA8)
 		texture.SetData(rgba.Pix)
 	default:
-		return fmt.Errorf("invalid color format: %v", img.ColorModel())
+		return nil, fmt.Errorf("invalid color format: %v", img.ColorModel())
 	}
 
-	return h.Add(name, texture)
+	return func() error {
+		return h.Add(name, texture)
+	}, nil
 }
 
 func (h *ImageHandler) Add(name string, texture *Texture2D) error {
@@ -130,7 +139,7 @@ func (h *ImageHandler) Add(name string, texture *Texture2D) error {
 		return err
 	}
 
-	h.Items[name] = texture.ID()
+	h.add(name, texture)
 
 	return nil
 }
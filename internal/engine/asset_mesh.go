@@ -39,6 +39,8 @@ const (
 const (
 	ErrMeshInvalidFaceType = Error("invalid model face type")
 	ErrMeshMissingFaces    = Error("model has no faces")
+	ErrMeshInvalidSkin     = Error("model skin does not match its vertices")
+	ErrMeshInvalidMorph    = Error("model morph target does not match its vertices")
 )
 
 const (
@@ -65,6 +67,26 @@ type MeshMetadata struct {
 	N     []mgl32.Vec3 `json:"n"`
 	T     []mgl32.Vec2 `json:"t"`
 	F     []Face       `json:"f"`
+
+	// Skin data. J and W hold the joints and weights of each vertex and
+	// are indexed like V. Joints holds the joint paths and BindPoses their
+	// inverse bind matrices.
+	J         [][4]uint32  `json:"j,omitempty"`
+	W         []mgl32.Vec4 `json:"w,omitempty"`
+	Joints    []string     `json:"joints,omitempty"`
+	BindPoses []mgl32.Mat4 `json:"bind_poses,omitempty"`
+
+	// Morph targets.
+	Morphs []MorphTargetMetadata `json:"morphs,omitempty"`
+}
+
+// MorphTargetMetadata is the file representation of a MorphTarget. V holds
+// the position offsets and is indexed like the V of the mesh. N holds the
+// optional normal offsets and is indexed like N.
+type MorphTargetMetadata struct {
+	Name string       `json:"name"`
+	V    []mgl32.Vec3 `json:"v"`
+	N    []mgl32.Vec3 `json:"n,omitempty"`
 }
 
 type MeshHandler struct {
@@ -72,32 +94,69 @@ type MeshHandler struct {
 }
 
 var _ AssetHandler = &MeshHandler{}
+var _ AssetDecoder = &MeshHandler{}
 
 // Load will load data from the reader.
 func (h *MeshHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode builds the vertex data of the mesh and returns the step which
+// allocates its buffers.
+func (h *MeshHandler) Decode(r *Resource) (func() error, error) {
 	metadata := &MeshMetadata{}
 	m := NewMesh()
 
 	dec := gob.NewDecoder(r.Reader())
 	err := dec.Decode(&metadata)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	name := metadata.Name
 
-	if _, dup := h.Items[name]; dup {
-		return ErrAssetExists(name)
-	}
-
 	if len(metadata.F) == 0 {
-		return ErrMeshMissingFaces
+		return nil, ErrMeshMissingFaces
 	}
 
 	v := make([]mgl32.Vec3, len(metadata.F)*3)
 	n := make([]mgl32.Vec3, len(metadata.F)*3)
 	t := make([]mgl32.Vec2, len(metadata.F)*3)
 
+	skinned := len(metadata.J) != 0
+	if skinned && (len(metadata.J) != len(metadata.V) || len(metadata.W) != len(metadata.V)) {
+		return nil, ErrMeshInvalidSkin
+	}
+
+	for _, morph := range metadata.Morphs {
+		if len(morph.V) != len(metadata.V) || (len(morph.N) != 0 && len(morph.N) != len(metadata.N)) {
+			return nil, ErrMeshInvalidMorph
+		}
+	}
+
+	morphs := make([]*MorphTarget, len(metadata.Morphs))
+	for k := range morphs {
+		morphs[k] = &MorphTarget{
+			Name:      metadata.Morphs[k].Name,
+			Positions: make([]mgl32.Vec3, len(metadata.F)*3),
+		}
+		if len(metadata.Morphs[k].N) != 0 && (metadata.FType == FaceTypeVN || metadata.FType == FaceTypeVTN) {
+			morphs[k].Normals = make([]mgl32.Vec3, len(metadata.F)*3)
+		}
+	}
+
+	var joints [][4]uint32
+	var weights []mgl32.Vec4
+	if skinned {
+		joints = make([][4]uint32, len(metadata.F)*3)
+		weights = make([]mgl32.Vec4, len(metadata.F)*3)
+	}
+
 	for i := range metadata.F {
 		for j := range metadata.F[i] {
 			switch metadata.FType {
@@ -114,16 +173,40 @@ func (h *MeshHandler) Load(r *Resource) error {
 				t[i*3+j] = metadata.T[metadata.F[i][j][FaceTexture]]
 				n[i*3+j] = metadata.N[metadata.F[i][j][FaceNormal]]
 			default:
-				return ErrMeshInvalidFaceType
+				return nil, ErrMeshInvalidFaceType
+			}
+
+			if skinned {
+				joints[i*3+j] = metadata.J[metadata.F[i][j][FaceVertex]]
+				weights[i*3+j] = metadata.W[metadata.F[i][j][FaceVertex]]
+			}
+
+			for k := range morphs {
+				morphs[k].Positions[i*3+j] = metadata.Morphs[k].V[metadata.F[i][j][FaceVertex]]
+				if morphs[k].Normals != nil {
+					morphs[k].Normals[i*3+j] = metadata.Morphs[k].N[metadata.F[i][j][FaceNormal]]
+				}
 			}
 		}
 	}
 
+	m.SetName(name)
 	m.SetVertices(v)
 	m.SetNormals(n)
 	m.SetUvs(t)
+	if skinned {
+		m.SetSkin(joints, weights)
+		m.SetJoints(metadata.Joints, metadata.BindPoses)
+	}
+	for k := range morphs {
+		if err := m.AddMorphTarget(morphs[k]); err != nil {
+			return nil, err
+		}
+	}
 
-	return h.Add(name, m)
+	return func() error {
+		return h.Add(name, m)
+	}, nil
 }
 
 func (h *MeshHandler) Add(name string, mesh *Mesh) error {
@@ -138,7 +221,7 @@ func (h *MeshHandler) Add(name string, mesh *Mesh) error {
 		return err
 	}
 
-	h.Items[name] = mesh.ID()
+	h.add(name, mesh)
 
 	return nil
 }
@@ -0,0 +1,432 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	AssetNamePrefab = "prefab" // Identifier is the type name of this asset.
+)
+
+// Prefab errors
+const (
+	ErrPrefabMissingObject = Error("prefab has no object")
+)
+
+// ErrPrefabOverride reports that a prefab override could not be applied.
+type ErrPrefabOverride struct {
+	Override PrefabOverride
+	Reason   string
+}
+
+func (e ErrPrefabOverride) Error() string {
+	return fmt.Sprintf("prefab: override %s:%s.%s: %s", e.Override.Path, e.Override.Component, e.Override.Property, e.Reason)
+}
+
+// ErrComponentNotCloneable reports that a component does not implement
+// ComponentCloner.
+type ErrComponentNotCloneable struct {
+	Component Component
+}
+
+func (e ErrComponentNotCloneable) Error() string {
+	return fmt.Sprintf("clone: component %s of type %T is not cloneable", e.Component.Name(), e.Component)
+}
+
+// PrefabMetadata is the file representation of a Prefab. Prefab files use
+// the same encodings as scene files.
+type PrefabMetadata struct {
+	Version int                 `json:"version"`
+	Name    string              `json:"name"`
+	Object  *GameObjectMetadata `json:"object"`
+}
+
+// PrefabOverride replaces a single property of an object in a prefab
+// instance. Path is the slash separated path of the object relative to the
+// instance root, empty for the root itself. Component is the registered type
+// name of the component, "Transform" for the transform, or empty for the
+// object's own "name" and "active" properties. Value is JSON encoded.
+type PrefabOverride struct {
+	Path      string          `json:"path"`
+	Component string          `json:"component"`
+	Property  string          `json:"property"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// PrefabLink associates a prefab instance with its source prefab and the
+// overrides applied on top of it.
+type PrefabLink struct {
+	prefab    *Prefab
+	overrides []PrefabOverride
+}
+
+// Prefab is a reusable GameObject hierarchy loaded through the asset system.
+type Prefab struct {
+	BaseObject
+
+	metadata *PrefabMetadata
+}
+
+type PrefabHandler struct {
+	BaseAssetHandler
+}
+
+var _ AssetHandler = &PrefabHandler{}
+var _ AssetDecoder = &PrefabHandler{}
+
+// Metadata returns the decoded contents of the prefab file.
+func (p *Prefab) Metadata() *PrefabMetadata {
+	return p.metadata
+}
+
+// NewPrefab creates a new prefab object from metadata.
+func NewPrefab(metadata *PrefabMetadata) *Prefab {
+	p := &Prefab{
+		metadata: metadata,
+	}
+
+	p.SetName(metadata.Name)
+	GetInstance().MustAssign(p)
+
+	return p
+}
+
+// NewPrefabFromGameObject creates a new prefab from an existing GameObject
+// hierarchy.
+func NewPrefabFromGameObject(name string, g *GameObject) (*Prefab, error) {
+	m, err := NewGameObjectMetadata(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrefab(&PrefabMetadata{
+		Version: SceneFileVersion,
+		Name:    name,
+		Object:  m,
+	}), nil
+}
+
+// Load will load data from the reader.
+func (h *PrefabHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode decodes the prefab file and returns the step which adds the prefab
+// to the handler.
+func (h *PrefabHandler) Decode(r *Resource) (func() error, error) {
+	metadata, err := DecodePrefab(r.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	name := metadata.Name
+
+	return func() error {
+		return h.Add(name, NewPrefab(metadata))
+	}, nil
+}
+
+func (h *PrefabHandler) Add(name string, prefab *Prefab) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.add(name, prefab)
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *PrefabHandler) Get(name string) (*Prefab, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*Prefab)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *PrefabHandler) MustGet(name string) *Prefab {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *PrefabHandler) Name() string {
+	return AssetNamePrefab
+}
+
+func NewPrefabHandler() *PrefabHandler {
+	h := &PrefabHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// DecodePrefab reads prefab metadata from the reader. The format is detected
+// in the same way as DecodeScene.
+func DecodePrefab(r io.Reader) (*PrefabMetadata, error) {
+	metadata := &PrefabMetadata{}
+
+	if err := decodeSceneFile(r, metadata, &metadata.Version); err != nil {
+		return nil, err
+	}
+	if metadata.Object == nil {
+		return nil, ErrPrefabMissingObject
+	}
+
+	return metadata, nil
+}
+
+// EncodePrefab writes prefab metadata to the writer in the given format.
+func EncodePrefab(w io.Writer, metadata *PrefabMetadata, format SceneFormat) error {
+	return encodeSceneFile(w, metadata, format)
+}
+
+// Instantiate creates a new instance of the prefab with the given overrides
+// applied. If parent is part of a scene, the instance is added to that scene's
+// graph, otherwise it is only attached to the parent. A nil parent leaves the
+// instance detached.
+func Instantiate(prefab *Prefab, parent *GameObject, overrides ...PrefabOverride) (*GameObject, error) {
+	if prefab.metadata == nil || prefab.metadata.Object == nil {
+		return nil, ErrPrefabMissingObject
+	}
+
+	g, err := NewGameObjectFromMetadata(prefab.metadata.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	g.prefab = &PrefabLink{
+		prefab: prefab,
+	}
+
+	for i := range overrides {
+		if err := g.prefab.SetOverride(g, overrides[i]); err != nil {
+			g.release()
+			return nil, err
+		}
+	}
+
+	if err := attachInstance(g, parent); err != nil {
+		g.release()
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Prefab returns the source prefab of the instance.
+func (l *PrefabLink) Prefab() *Prefab {
+	return l.prefab
+}
+
+// Overrides returns the overrides applied to the instance.
+func (l *PrefabLink) Overrides() []PrefabOverride {
+	return l.overrides
+}
+
+// SetOverride applies an override to the instance root and records it. An
+// existing override of the same property is replaced.
+func (l *PrefabLink) SetOverride(root *GameObject, o PrefabOverride) error {
+	if err := applyPrefabOverride(root, o); err != nil {
+		return err
+	}
+
+	for i := range l.overrides {
+		if l.overrides[i].Path == o.Path && l.overrides[i].Component == o.Component && l.overrides[i].Property == o.Property {
+			l.overrides[i] = o
+			return nil
+		}
+	}
+
+	l.overrides = append(l.overrides, o)
+
+	return nil
+}
+
+func (l *PrefabLink) clone() *PrefabLink {
+	c := &PrefabLink{
+		prefab:    l.prefab,
+		overrides: make([]PrefabOverride, len(l.overrides)),
+	}
+
+	copy(c.overrides, l.overrides)
+
+	return c
+}
+
+func attachInstance(g, parent *GameObject) error {
+	if parent == nil {
+		return nil
+	}
+
+	if s := parent.Scene(); s != nil {
+		return s.Graph().AddGameObject(g, parent)
+	}
+
+	parent.AddChild(g)
+	g.SetParent(parent)
+	g.OnParentChanged()
+
+	return nil
+}
+
+// cloneComponent creates a copy of the component with a fresh instance ID.
+func cloneComponent(c Component) (Component, error) {
+	if cloner, ok := c.(ComponentCloner); ok {
+		return cloner.CloneComponent()
+	}
+
+	return nil, ErrComponentNotCloneable{c}
+}
+
+func applyPrefabOverride(root *GameObject, o PrefabOverride) error {
+	// Override paths are always relative to the prefab root.
+	g := root.Find(strings.TrimPrefix(o.Path, "/"))
+	if g == nil {
+		return ErrPrefabOverride{o, "no such object"}
+	}
+
+	switch o.Component {
+	case "":
+		return applyObjectOverride(g, o)
+	case "Transform":
+		return applyTransformOverride(g, o)
+	}
+
+	components := g.Components()
+	for i := 1; i < len(components); i++ {
+		if name, ok := ComponentTypeName(components[i]); !ok || name != o.Component {
+			continue
+		}
+
+		properties := map[string]json.RawMessage{}
+
+		if e, ok := components[i].(PropertyEncoder); ok {
+			data, err := e.EncodeProperties()
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &properties); err != nil {
+				return err
+			}
+		}
+
+		properties[o.Property] = o.Value
+
+		data, err := json.Marshal(properties)
+		if err != nil {
+			return err
+		}
+
+		c, err := DecodeComponent(o.Component, data)
+		if err != nil {
+			return err
+		}
+
+		// Components are decoded from their properties, so the override
+		// replaces the component with a new one.
+		g.replaceComponent(components[i], c)
+
+		return nil
+	}
+
+	return ErrPrefabOverride{o, "no such component"}
+}
+
+func applyObjectOverride(g *GameObject, o PrefabOverride) error {
+	switch o.Property {
+	case "name":
+		var name string
+		if err := json.Unmarshal(o.Value, &name); err != nil {
+			return err
+		}
+		g.SetName(name)
+	case "active":
+		var active bool
+		if err := json.Unmarshal(o.Value, &active); err != nil {
+			return err
+		}
+		g.SetActive(active)
+	default:
+		return ErrPrefabOverride{o, "no such property"}
+	}
+
+	return nil
+}
+
+func applyTransformOverride(g *GameObject, o PrefabOverride) error {
+	t := g.Transform()
+
+	switch o.Property {
+	case "position":
+		var v mgl32.Vec3
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return err
+		}
+		t.SetPosition(v)
+	case "rotation":
+		var v mgl32.Vec4
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return err
+		}
+		t.SetRotation(mgl32.Quat{W: v[0], V: mgl32.Vec3{v[1], v[2], v[3]}})
+	case "scale":
+		var v mgl32.Vec3
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return err
+		}
+		t.SetScale(v)
+	default:
+		return ErrPrefabOverride{o, "no such property"}
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testOverrideScript is a registered script component with a property.
+type testOverrideScript struct {
+	testScript
+
+	value int
+}
+
+type testOverrideProperties struct {
+	Value int `json:"value"`
+}
+
+func init() {
+	RegisterComponentType("testOverrideScript", &testOverrideScript{}, decodeTestOverrideScript)
+}
+
+func newTestOverrideScript(value int) *testOverrideScript {
+	c := &testOverrideScript{value: value}
+
+	c.SetName("TestOverrideScript")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func (c *testOverrideScript) CloneComponent() (Component, error) {
+	return newTestOverrideScript(c.value), nil
+}
+
+func (c *testOverrideScript) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&testOverrideProperties{Value: c.value})
+}
+
+func decodeTestOverrideScript(properties []byte) (Component, error) {
+	p := &testOverrideProperties{}
+	if err := json.Unmarshal(properties, p); err != nil {
+		return nil, err
+	}
+
+	return newTestOverrideScript(p.Value), nil
+}
+
+// testPlainScript is a script component which cannot be cloned.
+type testPlainScript struct {
+	BaseScriptComponent
+}
+
+func newTestPlainScript() *testPlainScript {
+	c := &testPlainScript{}
+
+	c.SetName("TestPlainScript")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func newTestPrefabObject() *GameObject {
+	root := NewGameObject("root")
+	root.SetTag("enemy")
+	root.Transform().SetPosition(mgl32.Vec3{1, 2, 3})
+	root.AddComponent(newTestOverrideScript(2))
+
+	child := NewGameObject("child")
+	child.AddComponent(newTestOverrideScript(3))
+	root.AddChild(child)
+	child.SetParent(root)
+
+	return root
+}
+
+func overrideScript(g *GameObject) *testOverrideScript {
+	for _, c := range g.Components() {
+		if s, ok := c.(*testOverrideScript); ok {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func TestGameObject_Clone(t *testing.T) {
+	setupTestApp(t)
+
+	g := newTestPrefabObject()
+
+	c, err := g.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ID() == g.ID() || c.Name() != "root" || c.Tag() != "enemy" {
+		t.Errorf("clone id %d name %q tag %q", c.ID(), c.Name(), c.Tag())
+	}
+	if c.Parent() != nil || c.Scene() != nil {
+		t.Error("clone should be detached")
+	}
+	if c.Transform().Position() != (mgl32.Vec3{1, 2, 3}) {
+		t.Errorf("clone position %v", c.Transform().Position())
+	}
+
+	s, cs := overrideScript(g), overrideScript(c)
+	if cs == nil || cs == s || cs.ID() == s.ID() || cs.value != 2 {
+		t.Fatalf("script not copied: %+v", cs)
+	}
+	if cs.GameObject() != c {
+		t.Error("script not attached to the copy")
+	}
+
+	if len(c.Children()) != 1 {
+		t.Fatalf("%d children copied, expected 1", len(c.Children()))
+	}
+	child := c.Children()[0]
+	if child == g.Children()[0] || child.Parent() != c {
+		t.Fatal("child not copied")
+	}
+	if cs := overrideScript(child); cs == nil || cs.value != 3 || cs.GameObject() != child {
+		t.Errorf("child script not copied: %+v", cs)
+	}
+}
+
+func TestGameObject_CloneNotCloneable(t *testing.T) {
+	a := setupTestApp(t)
+
+	g := NewGameObject("root")
+	g.AddComponent(newTestOverrideScript(1))
+	child := NewGameObject("child")
+	child.AddComponent(newTestPlainScript())
+	g.AddChild(child)
+	child.SetParent(g)
+
+	objects := len(a.Instance().objects)
+
+	_, err := g.Clone()
+
+	var e ErrComponentNotCloneable
+	if !errors.As(err, &e) {
+		t.Fatalf("error %v, expected ErrComponentNotCloneable", err)
+	}
+	if n := len(a.Instance().objects); n != objects {
+		t.Errorf("%d objects after failed clone, expected %d", n, objects)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	setupTestApp(t)
+
+	s := NewScene("test")
+	s.Setup()
+
+	prefab, err := NewPrefabFromGameObject("enemy", newTestPrefabObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detached, err := Instantiate(prefab, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detached.Parent() != nil || detached.Scene() != nil {
+		t.Error("instance without parent should be detached")
+	}
+	if detached.Prefab() == nil || detached.Prefab().Prefab() != prefab {
+		t.Error("instance not linked to its prefab")
+	}
+	if overrideScript(detached) == nil || len(detached.Children()) != 1 {
+		t.Error("instance hierarchy not created")
+	}
+
+	parent := NewGameObject("parent")
+	if err := s.Graph().AddGameObject(parent, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := Instantiate(prefab, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Parent() != parent || g.Scene() != s {
+		t.Error("instance not added below its parent")
+	}
+
+	// Clones of an instance keep their own copy of the prefab link.
+	c, err := g.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Prefab() == nil || c.Prefab() == g.Prefab() || c.Prefab().Prefab() != prefab {
+		t.Error("clone prefab link not copied")
+	}
+}
+
+func TestInstantiate_Overrides(t *testing.T) {
+	a := setupTestApp(t)
+
+	prefab, err := NewPrefabFromGameObject("enemy", newTestPrefabObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := func(v interface{}) json.RawMessage {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	g, err := Instantiate(prefab, nil,
+		PrefabOverride{Property: "name", Value: value("boss")},
+		PrefabOverride{Path: "child", Property: "active", Value: value(false)},
+		PrefabOverride{Component: "Transform", Property: "position", Value: value(mgl32.Vec3{4, 5, 6})},
+		PrefabOverride{Component: "testOverrideScript", Property: "value", Value: value(3)},
+		PrefabOverride{Component: "testOverrideScript", Property: "value", Value: value(4)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Name() != "boss" {
+		t.Errorf("name %q, expected boss", g.Name())
+	}
+	if child := g.Children()[0]; child.Active() {
+		t.Error("child active override not applied")
+	}
+	if g.Transform().Position() != (mgl32.Vec3{4, 5, 6}) {
+		t.Errorf("position %v", g.Transform().Position())
+	}
+	if s := overrideScript(g); s == nil || s.value != 4 || s.GameObject() != g {
+		t.Errorf("script override not applied: %+v", s)
+	}
+	if n := len(g.Prefab().Overrides()); n != 4 {
+		t.Errorf("%d overrides recorded, expected 4", n)
+	}
+
+	tests := []PrefabOverride{
+		{Path: "missing", Property: "name", Value: value("x")},
+		{Property: "missing", Value: value("x")},
+		{Component: "Transform", Property: "missing", Value: value(1)},
+		{Component: "Camera", Property: "fov", Value: value(1)},
+	}
+
+	objects := len(a.Instance().objects)
+
+	for _, o := range tests {
+		_, err := Instantiate(prefab, nil, o)
+
+		var e ErrPrefabOverride
+		if !errors.As(err, &e) {
+			t.Errorf("override %+v: error %v, expected ErrPrefabOverride", o, err)
+		}
+	}
+
+	if n := len(a.Instance().objects); n != objects {
+		t.Errorf("%d objects after failed instantiations, expected %d", n, objects)
+	}
+}
+
+func TestPrefabLink_SetOverrideLifecycle(t *testing.T) {
+	s := newTestScene(t)
+
+	g := NewGameObject("enemy")
+	g.AddComponent(newTestOverrideScript(1))
+	prefab, err := NewPrefabFromGameObject("enemy", g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := Instantiate(prefab, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustAdd(t, s, instance, nil)
+
+	old := instance.Components()[1].(*testOverrideScript)
+	id := old.ID()
+	task, err := After(old, 1, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := PrefabOverride{Component: "testOverrideScript", Property: "value", Value: json.RawMessage("2")}
+	if err := instance.Prefab().SetOverride(instance, o); err != nil {
+		t.Fatal(err)
+	}
+
+	c, ok := instance.Components()[1].(*testOverrideScript)
+	if !ok || c == old || c.value != 2 || c.GameObject() != instance {
+		t.Fatalf("component not replaced: %+v", instance.Components())
+	}
+	if old.deactivates != 1 || old.destroys != 1 || c.activates != 1 {
+		t.Errorf("old deactivates %d destroys %d, new activates %d, expected 1", old.deactivates, old.destroys, c.activates)
+	}
+	if !task.Done() {
+		t.Error("task of the replaced component was not cancelled")
+	}
+	if _, err := GetInstance().Get(id); err == nil {
+		t.Error("replaced component not released")
+	}
+}
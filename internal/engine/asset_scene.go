@@ -0,0 +1,399 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"unicode"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AssetNameScene = "scene" // Identifier is the type name of this asset.
+)
+
+// SceneFileVersion is the current version of the scene file format.
+const SceneFileVersion = 1
+
+// SceneFormat is the encoding used for a scene file.
+type SceneFormat int
+
+const (
+	SceneFormatJSON   SceneFormat = iota // SceneFormatJSON is a human readable JSON encoding.
+	SceneFormatBinary                    // SceneFormatBinary is a compact gob encoding.
+)
+
+// Scene file errors
+const (
+	ErrSceneFormat = Error("unknown scene file format")
+)
+
+// ErrSceneVersion reports that a scene file has an unsupported version.
+type ErrSceneVersion int
+
+func (e ErrSceneVersion) Error() string {
+	return fmt.Sprintf("scene file: unsupported version %d", int(e))
+}
+
+// SceneMetadata is the file representation of a Scene.
+type SceneMetadata struct {
+	Version int                   `json:"version"`
+	Name    string                `json:"name"`
+	Objects []*GameObjectMetadata `json:"objects"`
+}
+
+// GameObjectMetadata is the file representation of a GameObject and its
+// children.
+type GameObjectMetadata struct {
+	Name       string                `json:"name"`
+	Active     bool                  `json:"active"`
+	Tag        string                `json:"tag,omitempty"`
+	Layer      LayerMask             `json:"layer,omitempty"`
+	Transform  TransformMetadata     `json:"transform"`
+	Components []ComponentMetadata   `json:"components,omitempty"`
+	Children   []*GameObjectMetadata `json:"children,omitempty"`
+}
+
+// TransformMetadata is the file representation of a local Transform. The
+// rotation is stored as W, X, Y, Z.
+type TransformMetadata struct {
+	Position mgl32.Vec3 `json:"position"`
+	Rotation mgl32.Vec4 `json:"rotation"`
+	Scale    mgl32.Vec3 `json:"scale"`
+}
+
+// ComponentMetadata is the file representation of a registered component.
+type ComponentMetadata struct {
+	Type       string          `json:"type"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+}
+
+// SceneFile is a scene loaded through the asset system.
+type SceneFile struct {
+	BaseObject
+
+	metadata *SceneMetadata
+}
+
+type SceneHandler struct {
+	BaseAssetHandler
+}
+
+var _ AssetHandler = &SceneHandler{}
+var _ AssetDecoder = &SceneHandler{}
+
+// Metadata returns the decoded contents of the scene file.
+func (f *SceneFile) Metadata() *SceneMetadata {
+	return f.metadata
+}
+
+// NewSceneFile creates a new scene file object from metadata.
+func NewSceneFile(metadata *SceneMetadata) *SceneFile {
+	f := &SceneFile{
+		metadata: metadata,
+	}
+
+	f.SetName(metadata.Name)
+	GetInstance().MustAssign(f)
+
+	return f
+}
+
+// Load will load data from the reader.
+func (h *SceneHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode decodes the scene file and returns the step which adds it to the
+// handler.
+func (h *SceneHandler) Decode(r *Resource) (func() error, error) {
+	metadata, err := DecodeScene(r.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	name := metadata.Name
+
+	return func() error {
+		return h.Add(name, NewSceneFile(metadata))
+	}, nil
+}
+
+func (h *SceneHandler) Add(name string, file *SceneFile) error {
+	h.Mu.Lock()
+	defer h.Mu.Unlock()
+
+	if _, dup := h.Items[name]; dup {
+		return ErrAssetExists(name)
+	}
+
+	h.add(name, file)
+
+	return nil
+}
+
+// Get gets an asset by name.
+func (h *SceneHandler) Get(name string) (*SceneFile, error) {
+	a, err := h.GetAsset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, ok := a.(*SceneFile)
+	if !ok {
+		return nil, ErrAssetType(name)
+	}
+
+	return a2, nil
+}
+
+// MustGet is like GetAsset, but panics if an error occurs.
+func (h *SceneHandler) MustGet(name string) *SceneFile {
+	a, err := h.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (h *SceneHandler) Name() string {
+	return AssetNameScene
+}
+
+func NewSceneHandler() *SceneHandler {
+	h := &SceneHandler{}
+	h.Items = make(map[string]uint32)
+	h.Mu = &sync.RWMutex{}
+
+	return h
+}
+
+// DecodeScene reads scene metadata from the reader. The format is detected
+// from the content: JSON files start with '{', anything else is treated as
+// the binary format.
+func DecodeScene(r io.Reader) (*SceneMetadata, error) {
+	metadata := &SceneMetadata{}
+
+	if err := decodeSceneFile(r, metadata, &metadata.Version); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// EncodeScene writes scene metadata to the writer in the given format.
+func EncodeScene(w io.Writer, metadata *SceneMetadata, format SceneFormat) error {
+	return encodeSceneFile(w, metadata, format)
+}
+
+// decodeSceneFile decodes a file in one of the scene file formats into v and
+// checks the version decoded into version.
+func decodeSceneFile(r io.Reader, v interface{}, version *int) error {
+	br := bufio.NewReader(r)
+
+	format, err := detectSceneFormat(br)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case SceneFormatJSON:
+		err = json.NewDecoder(br).Decode(v)
+	case SceneFormatBinary:
+		err = gob.NewDecoder(br).Decode(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *version < 1 || *version > SceneFileVersion {
+		return ErrSceneVersion(*version)
+	}
+
+	return nil
+}
+
+// encodeSceneFile encodes v in the given scene file format.
+func encodeSceneFile(w io.Writer, v interface{}, format SceneFormat) error {
+	switch format {
+	case SceneFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(v)
+	case SceneFormatBinary:
+		return gob.NewEncoder(w).Encode(v)
+	}
+
+	return ErrSceneFormat
+}
+
+// LoadSceneFromFile reads scene metadata from file.
+func LoadSceneFromFile(filename string) (*SceneMetadata, error) {
+	r, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return DecodeScene(r)
+}
+
+// SaveSceneToFile writes scene metadata to file.
+func SaveSceneToFile(filename string, metadata *SceneMetadata, format SceneFormat) error {
+	w, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return EncodeScene(w, metadata, format)
+}
+
+// NewGameObjectMetadata captures a GameObject, its registered components and
+// its children. Components without a registered type are skipped.
+func NewGameObjectMetadata(g *GameObject) (*GameObjectMetadata, error) {
+	t := g.Transform()
+	r := t.Rotation()
+
+	m := &GameObjectMetadata{
+		Name:   g.Name(),
+		Active: g.Active(),
+		Tag:    g.Tag(),
+		Layer:  g.Layer(),
+		Transform: TransformMetadata{
+			Position: t.Position(),
+			Rotation: mgl32.Vec4{r.W, r.V[0], r.V[1], r.V[2]},
+			Scale:    t.Scale(),
+		},
+	}
+
+	components := g.Components()
+	for i := 1; i < len(components); i++ {
+		typeName, ok := ComponentTypeName(components[i])
+		if !ok {
+			logrus.Warnf("Skipping unregistered component %s on %s", components[i].Name(), g.Name())
+			continue
+		}
+
+		cm := ComponentMetadata{Type: typeName}
+
+		if e, ok := components[i].(PropertyEncoder); ok {
+			data, err := e.EncodeProperties()
+			if err != nil {
+				return nil, err
+			}
+			cm.Properties = data
+		}
+
+		m.Components = append(m.Components, cm)
+	}
+
+	children := g.Children()
+	for i := range children {
+		cm, err := NewGameObjectMetadata(children[i])
+		if err != nil {
+			return nil, err
+		}
+
+		m.Children = append(m.Children, cm)
+	}
+
+	return m, nil
+}
+
+// NewGameObjectFromMetadata creates a new GameObject hierarchy from metadata.
+// The returned object has not been added to a scene graph.
+func NewGameObjectFromMetadata(m *GameObjectMetadata) (*GameObject, error) {
+	g := NewGameObject(m.Name)
+	g.tag = m.Tag
+
+	// Files written before layers existed leave the object on the default layer.
+	if m.Layer != LayerMaskNone {
+		g.layer = m.Layer
+	}
+
+	t := g.Transform()
+	t.SetScale(m.Transform.Scale)
+	t.SetRotation(mgl32.Quat{W: m.Transform.Rotation[0], V: mgl32.Vec3{m.Transform.Rotation[1], m.Transform.Rotation[2], m.Transform.Rotation[3]}})
+	t.SetPosition(m.Transform.Position)
+
+	for i := range m.Components {
+		c, err := DecodeComponent(m.Components[i].Type, m.Components[i].Properties)
+		if err != nil {
+			g.release()
+			return nil, err
+		}
+
+		g.AddComponent(c)
+	}
+
+	for i := range m.Children {
+		child, err := NewGameObjectFromMetadata(m.Children[i])
+		if err != nil {
+			g.release()
+			return nil, err
+		}
+
+		g.AddChild(child)
+		child.SetParent(g)
+		child.OnParentChanged()
+	}
+
+	g.SetActive(m.Active)
+
+	return g, nil
+}
+
+func detectSceneFormat(r *bufio.Reader) (SceneFormat, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		if unicode.IsSpace(rune(b[0])) {
+			r.ReadByte()
+			continue
+		}
+
+		if b[0] == '{' {
+			return SceneFormatJSON, nil
+		}
+
+		return SceneFormatBinary, nil
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func newTestSceneForSave(t *testing.T) *Scene {
+	scene := NewScene("saved")
+	scene.Setup()
+
+	parent := NewGameObject("parent")
+	parent.SetTag("enemy")
+	parent.SetLayer(LayerUI)
+	parent.Transform().SetPosition(mgl32.Vec3{1, 2, 3})
+	parent.Transform().SetScale(mgl32.Vec3{2, 2, 2})
+	parent.Transform().SetRotation(mgl32.QuatRotate(1, mgl32.Vec3{0, 1, 0}))
+
+	animator := NewAnimator()
+	animator.SetAutoplay("idle")
+	animator.SetSpeed(2)
+	parent.AddComponent(animator)
+
+	child := NewGameObject("child")
+	child.SetActive(false)
+	parent.AddChild(child)
+	child.SetParent(parent)
+
+	if err := scene.Graph().AddGameObject(parent, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return scene
+}
+
+func checkLoadedScene(t *testing.T, m *SceneMetadata) {
+	scene := NewScene("loaded")
+	scene.Setup()
+
+	if err := scene.LoadMetadata(m); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := scene.Graph().Find("parent")
+	if parent == nil {
+		t.Fatal("parent not loaded")
+	}
+	if parent.Tag() != "enemy" || parent.Layer() != LayerUI {
+		t.Errorf("tag %q layer %v", parent.Tag(), parent.Layer())
+	}
+
+	tr := parent.Transform()
+	if tr.Position() != (mgl32.Vec3{1, 2, 3}) || tr.Scale() != (mgl32.Vec3{2, 2, 2}) {
+		t.Errorf("position %v scale %v", tr.Position(), tr.Scale())
+	}
+	if !tr.Rotation().ApproxEqual(mgl32.QuatRotate(1, mgl32.Vec3{0, 1, 0})) {
+		t.Errorf("rotation %v", tr.Rotation())
+	}
+
+	animator := AnimatorComponent(parent)
+	if animator == nil || animator.autoplay != "idle" || animator.Speed() != 2 {
+		t.Errorf("animator not restored: %+v", animator)
+	}
+
+	child := parent.Find("child")
+	if child == nil || child.Active() {
+		t.Errorf("child not restored inactive: %v", child)
+	}
+}
+
+func TestScene_SaveLoad(t *testing.T) {
+	setupTestApp(t)
+
+	scene := newTestSceneForSave(t)
+
+	for _, format := range []SceneFormat{SceneFormatJSON, SceneFormatBinary} {
+		buf := &bytes.Buffer{}
+		if err := scene.Save(buf, format); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := DecodeScene(buf)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if m.Version != SceneFileVersion || m.Name != "saved" {
+			t.Errorf("format %d: version %d name %q", format, m.Version, m.Name)
+		}
+
+		checkLoadedScene(t, m)
+	}
+
+	filename := filepath.Join(t.TempDir(), "scene.json")
+	if err := scene.SaveToFile(filename, SceneFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadSceneFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLoadedScene(t, m)
+}
+
+func TestDecodeScene_Version(t *testing.T) {
+	for _, data := range []string{
+		`{"version": 0, "name": "old", "objects": []}`,
+		`{"version": 2, "name": "new", "objects": []}`,
+	} {
+		if _, err := DecodeScene(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected version error", data)
+		} else if _, ok := err.(ErrSceneVersion); !ok {
+			t.Errorf("%s: expected ErrSceneVersion, got %v", data, err)
+		}
+	}
+
+	if err := EncodeScene(&bytes.Buffer{}, &SceneMetadata{}, SceneFormat(7)); err != ErrSceneFormat {
+		t.Errorf("expected ErrSceneFormat, got %v", err)
+	}
+}
+
+func TestDetectSceneFormat(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		format SceneFormat
+	}{
+		{`{"version": 1}`, SceneFormatJSON},
+		{" \n\t{", SceneFormatJSON},
+		{"\x1f\xff\x81", SceneFormatBinary},
+	} {
+		format, err := detectSceneFormat(bufio.NewReader(strings.NewReader(c.data)))
+		if err != nil || format != c.format {
+			t.Errorf("%q: format %d, %v, expected %d", c.data, format, err, c.format)
+		}
+	}
+
+	if _, err := detectSceneFormat(bufio.NewReader(strings.NewReader("  "))); err == nil {
+		t.Error("expected error for empty scene file")
+	}
+}
+
+func TestSceneHandler_Add(t *testing.T) {
+	setupTestApp(t)
+
+	h := NewSceneHandler()
+	f := NewSceneFile(&SceneMetadata{Version: SceneFileVersion, Name: "level"})
+
+	if err := h.Add("level", f); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("level", f); err == nil {
+		t.Error("expected error adding scene twice")
+	}
+
+	got, err := h.Get("level")
+	if err != nil || got.Metadata().Name != "level" {
+		t.Errorf("get: %v, %v", got, err)
+	}
+}
+
+func TestNewSceneFromAsset(t *testing.T) {
+	a := setupTestApp(t)
+
+	h, err := handlerOf[*SceneHandler](a.Asset(), AssetNameScene)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("level", NewSceneFile(&SceneMetadata{Version: SceneFileVersion, Name: "level"})); err != nil {
+		t.Fatal(err)
+	}
+
+	// The asset is resolved through the App the scene is registered with,
+	// not the current App.
+	setupTestApp(t)
+
+	s := NewSceneFromAsset("level", "level")
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := NewSceneFromAsset("missing", "missing")
+	if err := a.RegisterScene(missing); err != nil {
+		t.Fatal(err)
+	}
+	if err := missing.Load(); err == nil {
+		t.Error("expected error loading a missing scene asset")
+	}
+
+	if _, err := handlerOf[*SceneHandler](a.Asset(), AssetNamePrefab); err != ErrHandlerType(AssetNamePrefab) {
+		t.Errorf("got %v, expected ErrHandlerType", err)
+	}
+	if _, err := handlerOf[*SceneHandler](a.Asset(), "none"); err != ErrHandlerNotFound("none") {
+		t.Errorf("got %v, expected ErrHandlerNotFound", err)
+	}
+}
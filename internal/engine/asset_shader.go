@@ -34,6 +34,7 @@ const (
 )
 
 var _ AssetHandler = &ShaderHandler{}
+var _ AssetDecoder = &ShaderHandler{}
 
 type ShaderHandler struct {
 	BaseAssetHandler
@@ -47,21 +48,28 @@ type ShaderMetadata struct {
 
 // Load will load data from the reader.
 func (h *ShaderHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+// Decode reads the shader sources and returns the step which compiles them.
+func (h *ShaderHandler) Decode(r *Resource) (func() error, error) {
 	m := &ShaderMetadata{}
 	s := NewShader()
 
 	data, err := ioutil.ReadAll(r.Reader())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := json.Unmarshal(data, m); err != nil {
-		return err
+		return nil, err
 	}
 	name := m.Name
-	if _, dup := h.Items[name]; dup {
-		return ErrAssetExists(name)
-	}
 
 	s.SetName(m.Name)
 	s.deferredCapable = m.Deferred
@@ -70,16 +78,18 @@ func (h *ShaderHandler) Load(r *Resource) error {
 	for i := range m.Files {
 		r, err := NewResource(filepath.Join(r.DirPrefix(), m.Files[i]))
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if err := GetAsset().ReadResource(r); err != nil {
-			return err
+			return nil, err
 		}
 
 		s.AddData(r.Bytes())
 	}
 
-	return h.Add(name, s)
+	return func() error {
+		return h.Add(name, s)
+	}, nil
 }
 
 func (h *ShaderHandler) Add(name string, shader *Shader) error {
@@ -91,7 +101,7 @@ func (h *ShaderHandler) Add(name string, shader *Shader) error {
 		return err
 	}
 
-	h.Items[name] = shader.ID()
+	h.add(name, shader)
 
 	return nil
 }
@@ -59,6 +59,7 @@ var rotMatrices = [6]mgl32.Mat4{
 }
 
 var _ AssetHandler = &SkyboxHandler{}
+var _ AssetDecoder = &SkyboxHandler{}
 
 type SkyboxMetadata struct {
 	Name       string `json:"name"`
@@ -84,92 +85,112 @@ func (h *SkyboxHandler) Name() string {
 }
 
 func (h *SkyboxHandler) Load(r *Resource) error {
-	m := &SkyboxMetadata{}
-
-	if err := json.Unmarshal(r.Bytes(), m); err != nil {
+	upload, err := h.Decode(r)
+	if err != nil {
 		return err
 	}
 
-	if _, dup := h.Items[m.Name]; dup {
-		return ErrAssetExists(m.Name)
+	return upload()
+}
+
+// Decode reads and decodes the skybox images and returns the step which
+// uploads them and renders the cubemaps.
+func (h *SkyboxHandler) Decode(r *Resource) (func() error, error) {
+	m := &SkyboxMetadata{}
+
+	if err := json.Unmarshal(r.Bytes(), m); err != nil {
+		return nil, err
 	}
 
-	skybox, err := h.loadMap(m, r.DirPrefix())
+	textures, err := h.decodeMap(m, r.DirPrefix())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	h.Items[m.Name] = skybox.ID()
+	return func() error {
+		if _, dup := h.Items[m.Name]; dup {
+			return ErrAssetExists(m.Name)
+		}
 
-	return nil
-}
+		skybox, err := h.loadMap(textures)
+		if err != nil {
+			return err
+		}
 
-func (h *SkyboxHandler) loadMap(m *SkyboxMetadata, dir string) (skybox *Skybox, err error) {
-	skybox = NewSkybox(nil, nil, nil)
+		h.add(m.Name, skybox)
 
-	var specR, irrdR *Resource
-	var specTex, irrdTex *Texture2D
+		return nil
+	}, nil
+}
 
-	genSpecular := len(m.Specular) == 0
-	genIrradiance := len(m.Irradiance) == 0
+// skyboxTextures holds the decoded source textures of a skybox. Specular and
+// irradiance are nil when they are generated from the radiance map.
+type skyboxTextures struct {
+	radiance   *Texture2D
+	specular   *Texture2D
+	irradiance *Texture2D
+}
 
-	radiR, err := NewResource(filepath.Join(dir, m.Radiance))
-	if err != nil {
-		return nil, err
-	}
-	if err := GetAsset().ReadResource(radiR); err != nil {
-		return nil, err
+// decodeMap reads and decodes the source images of the skybox. It does not
+// use OpenGL.
+func (h *SkyboxHandler) decodeMap(m *SkyboxMetadata, dir string) (*skyboxTextures, error) {
+	textures := &skyboxTextures{}
+
+	files := []struct {
+		name    string
+		texture **Texture2D
+	}{
+		{m.Radiance, &textures.radiance},
+		{m.Specular, &textures.specular},
+		{m.Irradiance, &textures.irradiance},
 	}
 
-	if !genSpecular {
-		specR, err = NewResource(filepath.Join(dir, m.Specular))
+	for _, f := range files {
+		if len(f.name) == 0 {
+			continue
+		}
+
+		r, err := NewResource(filepath.Join(dir, f.name))
 		if err != nil {
 			return nil, err
 		}
-		if err := GetAsset().ReadResource(specR); err != nil {
+		if err := GetAsset().ReadResource(r); err != nil {
 			return nil, err
 		}
-	}
-	if !genIrradiance {
-		irrdR, err = NewResource(filepath.Join(dir, m.Irradiance))
+
+		*f.texture, err = loadTexture(loadImage(r))
 		if err != nil {
 			return nil, err
 		}
-		if err := GetAsset().ReadResource(irrdR); err != nil {
-			return nil, err
-		}
 	}
 
-	rimg := loadImage(radiR)
-	simg := loadImage(specR)
-	iimg := loadImage(irrdR)
+	return textures, nil
+}
 
-	radiTex, err := loadTexture(rimg)
-	if err != nil {
-		return nil, err
-	}
-	if !genSpecular {
-		specTex, err = loadTexture(simg)
-		if err != nil {
-			return nil, err
+// loadMap uploads the decoded textures and renders the skybox cubemaps.
+func (h *SkyboxHandler) loadMap(textures *skyboxTextures) (skybox *Skybox, err error) {
+	for _, tex := range []*Texture2D{textures.radiance, textures.specular, textures.irradiance} {
+		if tex == nil {
+			continue
 		}
-	}
-	if !genIrradiance {
-		irrdTex, err = loadTexture(iimg)
-		if err != nil {
+		if err := tex.Alloc(); err != nil {
 			return nil, err
 		}
 	}
 
+	skybox = NewSkybox(nil, nil, nil)
+
 	fbo := NewFramebufferRaw()
 	defer fbo.Dealloc()
 
+	radiTex := textures.radiance
+
 	skybox.radiance, err = makeCubemap(radiTex, fbo, radiTex.Size().Y()/2)
 	if err != nil {
 		return nil, err
 	}
 
-	if genSpecular {
+	if specTex := textures.specular; specTex == nil {
 		skybox.specular, err = generateSpecular(skybox.radiance, fbo)
 	} else {
 		skybox.specular, err = makeCubemap(specTex, fbo, specTex.Size().Y()/2)
@@ -178,7 +199,7 @@ func (h *SkyboxHandler) loadMap(m *SkyboxMetadata, dir string) (skybox *Skybox,
 		return nil, err
 	}
 
-	if genIrradiance {
+	if irrdTex := textures.irradiance; irrdTex == nil {
 		skybox.irradiance, err = generateIrradiance(skybox.radiance, fbo)
 	} else {
 		skybox.irradiance, err = makeCubemap(irrdTex, fbo, irrdTex.Size().Y()/2)
@@ -225,6 +246,8 @@ func loadImage(r *Resource) (img image.Image) {
 	return img
 }
 
+// loadTexture creates a texture from the image. The texture data is uploaded
+// when the texture is allocated.
 func loadTexture(img image.Image) (tex *Texture2D, err error) {
 	x := int32(img.Bounds().Dx())
 	y := int32(img.Bounds().Dy())
@@ -279,10 +302,6 @@ func loadTexture(img image.Image) (tex *Texture2D, err error) {
 		return nil, errors.New("unknown image type")
 	}
 
-	if err := tex.Alloc(); err != nil {
-		return nil, err
-	}
-
 	return tex, err
 }
 
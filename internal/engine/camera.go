@@ -23,6 +23,8 @@ SOFTWARE.
 package engine
 
 import (
+	"encoding/json"
+
 	"github.com/go-gl/gl/v4.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/sirupsen/logrus"
@@ -75,6 +77,23 @@ const (
 	ClearModeNothing
 )
 
+type cameraProperties struct {
+	RenderPath  RenderPath `json:"render_path"`
+	HDR         bool       `json:"hdr"`
+	ClearMode   ClearMode  `json:"clear_mode"`
+	ClearColor  Color      `json:"clear_color"`
+	Fov         float32    `json:"fov"`
+	NearClip    float32    `json:"near_clip"`
+	FarClip     float32    `json:"far_clip"`
+	CullingMask LayerMask  `json:"culling_mask"`
+
+	Orthographic bool `json:"orthographic,omitempty"`
+}
+
+func init() {
+	RegisterComponentType("Camera", &Camera{}, decodeCamera)
+}
+
 type Renderer interface {
 	Render(*Camera)
 	RenderShader(*Shader, *Camera)
@@ -97,6 +116,7 @@ type Camera struct {
 	normalMatrix     mgl32.Mat3
 	clearColor       Color
 	clearMode        ClearMode
+	cullingMask      LayerMask
 	renderPath       RenderPath
 	activeRenderPath RenderPath
 	aspectRatio      float32
@@ -113,15 +133,61 @@ func (c *Camera) SetClearMode(mode ClearMode) {
 	c.clearMode = mode
 }
 
+func (c *Camera) ClearMode() ClearMode {
+	return c.clearMode
+}
+
+func (c *Camera) SetClearColor(color Color) {
+	c.clearColor = color
+}
+
+func (c *Camera) ClearColor() Color {
+	return c.clearColor
+}
+
+// Render renders the camera and copies the result to the window.
 func (c *Camera) Render() {
+	c.render(false)
+}
+
+// render renders the camera. If composite is set the result is blended over
+// the window by its alpha instead of replacing it.
+func (c *Camera) render(composite bool) {
+	if c.framebuffer == nil {
+		return
+	}
+
+	profiler := GetProfiler()
+	name := c.profileName()
+
+	defer profiler.BeginGPU(name).End()
+
 	c.startRender()
 
+	scope := profiler.BeginGPU(name + "/deferred")
 	c.renderDeferred()
+	scope.End()
+
+	scope = profiler.BeginGPU(name + "/forward")
 	c.renderForward()
+	scope.End()
+
 	//c.renderNormals()
+
+	scope = profiler.BeginGPU(name + "/effects")
 	c.renderEffects()
+	scope.End()
 
-	c.endRender()
+	c.endRender(composite)
+}
+
+// profileName returns the name of the camera's profiler scopes.
+func (c *Camera) profileName() string {
+	if c.GameObject() == nil {
+		return "camera"
+	}
+
+	return "camera/" + c.GameObject().Name()
 }
 
 func (c *Camera) startRender() {
@@ -136,9 +202,34 @@ func (c *Camera) startRender() {
 	c.clearBackground()
 }
 
-func (c *Camera) endRender() {
+func (c *Camera) endRender(composite bool) {
 	UnbindCurrentFramebuffer()
-	BlitFramebuffers(c.framebuffer, nil, gl.COLOR_ATTACHMENT0)
+
+	if composite {
+		c.composite()
+	} else {
+		BlitFramebuffers(c.framebuffer, nil, gl.COLOR_ATTACHMENT0)
+	}
+}
+
+// composite draws the color buffer of the camera over the window with alpha
+// blending, so the output of cameras rendered before shows through.
+func (c *Camera) composite() {
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	c.shaders[CameraShaderCopy].Bind()
+	c.shaders[CameraShaderCopy].SetSubroutine(ShaderComponentFragment, "pass_0")
+	c.textures[CameraTextureLDR0].ActivateTexture(gl.TEXTURE0)
+
+	c.meshes[CameraMeshEffect].Bind()
+	c.meshes[CameraMeshEffect].Draw()
+	c.meshes[CameraMeshEffect].Unbind()
+	c.shaders[CameraShaderCopy].Unbind()
+
+	gl.Disable(gl.BLEND)
+	gl.Enable(gl.DEPTH_TEST)
 }
 
 func (c *Camera) clearBackground() {
@@ -233,6 +324,17 @@ func (c *Camera) SetFov(fov float32) {
 	c.fov = fov
 }
 
+// Orthographic reports if the camera uses an orthographic projection.
+func (c *Camera) Orthographic() bool {
+	return c.orthographic
+}
+
+// SetOrthographic sets if the camera uses an orthographic projection.
+func (c *Camera) SetOrthographic(orthographic bool) {
+	c.orthographic = orthographic
+	c.UpdateMatrices()
+}
+
 func (c *Camera) CameraPosition() mgl32.Vec3 {
 	return c.GetTransform().Position()
 }
@@ -253,14 +355,23 @@ func (c *Camera) AddEffect(effect Effect) {
 	c.effects = append(c.effects, effect)
 }
 
+// RemoveEffect removes the effect from the camera.
+func (c *Camera) RemoveEffect(effect Effect) {
+	for i := range c.effects {
+		if c.effects[i] == effect {
+			c.effects = append(c.effects[:i], c.effects[i+1:]...)
+			return
+		}
+	}
+}
+
 func (c *Camera) OnSceneGraphUpdate() {
 	c.deferredCache = c.deferredCache[:0]
 	c.forwardCache = c.forwardCache[:0]
 
 	var drawables []Renderer
-	components := c.GameObject().Scene().Graph().Components()
-	for i := range components {
-		if r, ok := components[i].(Renderer); ok {
+	for _, r := range FindComponents[Renderer](c.GameObject().Scene().Graph()) {
+		if !c.culls(r) {
 			drawables = append(drawables, r)
 		}
 	}
@@ -291,8 +402,7 @@ func (c *Camera) setupPipeline() {
 
 	c.shaders[CameraShaderCopy] = NewShaderUtilsCopy()
 	c.shaders[CameraShaderSkybox] = NewShaderUtilsSkybox()
-	// FIXME: Replace with real shader.
-	c.shaders[CameraShaderNormals] = NewShaderUtilsCopy()
+	c.shaders[CameraShaderNormals] = NewShaderUtilsNormals()
 
 	c.textures[CameraTextureLDR0] = NewTexture2D(size, TextureFormatDefaultColor)
 	c.textures[CameraTextureLDR1] = NewTexture2D(size, TextureFormatDefaultColor)
@@ -429,25 +539,19 @@ func (c *Camera) renderEffects() {
 			if c.effects[i].Type() == EffectTypeTonemapper {
 				c.effectActiveType = EffectTypeTonemapper
 
-				c.startEffectPass()
-				c.effects[i].Render(c)
-				c.endEffectPass()
+				c.renderEffect(c.effects[i])
 
 				c.effectActiveType = EffectTypeLDR
 
 				continue
 			}
 
-			c.startEffectPass()
-			c.effects[i].Render(c)
-			c.endEffectPass()
+			c.renderEffect(c.effects[i])
 		}
 	} else {
 		c.effectActiveType = EffectTypeLDR
 		for i := range c.effects {
-			c.startEffectPass()
-			c.effects[i].Render(c)
-			c.endEffectPass()
+			c.renderEffect(c.effects[i])
 		}
 	}
 
@@ -455,6 +559,16 @@ func (c *Camera) renderEffects() {
 	gl.DepthMask(true)
 }
 
+func (c *Camera) renderEffect(effect Effect) {
+	if profiler := GetProfiler(); profiler.Enabled() {
+		defer profiler.BeginGPU(c.profileName() + "/effect/" + effectName(effect)).End()
+	}
+
+	c.startEffectPass()
+	effect.Render(c)
+	c.endEffectPass()
+}
+
 func (c *Camera) EffectPass() {
 	if c.effectActiveType == EffectTypeHDR {
 		if c.effectPass%2 == 1 {
@@ -536,26 +650,23 @@ func NewCamera(renderPath RenderPath, hdr bool) *Camera {
 		farClip:       100000.0,
 		aspectRatio:   GetWindow().AspectRatio(),
 		clearColor:    ColorBlack(),
+		cullingMask:   LayerMaskAll,
 	}
 
 	c.SetName("Camera")
 	GetInstance().MustAssign(c)
 
-	c.setupPipeline()
+	// Headless cameras keep their matrices and caches, but have no pipeline.
+	if glAvailable() {
+		c.setupPipeline()
+	}
 	c.UpdateMatrices()
 
 	return c
 }
 
 func CameraComponent(g *GameObject) *Camera {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*Camera); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return GetComponent[*Camera](g)
 }
 
 func (c *Camera) Awake() {
@@ -570,9 +681,73 @@ func (c *Camera) Update() {
 
 func (c *Camera) Resize() {
 	c.aspectRatio = GetWindow().AspectRatio()
-	c.framebuffer.SetSize(GetWindow().Resolution())
-	if c.renderPath == RenderPathDeferred {
+	if c.framebuffer != nil {
+		c.framebuffer.SetSize(GetWindow().Resolution())
+	}
+	if c.gbuffer != nil {
 		c.gbuffer.SetSize(GetWindow().Resolution())
 	}
 	c.UpdateMatrices()
 }
+
+// CloneComponent returns a copy of the camera with its own pipeline. Effects
+// are shared with the original camera.
+func (c *Camera) CloneComponent() (Component, error) {
+	n := NewCamera(c.renderPath, c.hdr)
+	n.clearMode = c.clearMode
+	n.clearColor = c.clearColor
+	n.fov = c.fov
+	n.cullingMask = c.cullingMask
+	n.nearClip = c.nearClip
+	n.farClip = c.farClip
+	n.orthographic = c.orthographic
+	n.effects = make([]Effect, len(c.effects))
+	copy(n.effects, c.effects)
+	n.UpdateMatrices()
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the camera.
+func (c *Camera) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&cameraProperties{
+		RenderPath:  c.renderPath,
+		HDR:         c.hdr,
+		ClearMode:   c.clearMode,
+		ClearColor:  c.clearColor,
+		Fov:         c.fov,
+		NearClip:    c.nearClip,
+		FarClip:     c.farClip,
+		CullingMask: c.cullingMask,
+
+		Orthographic: c.orthographic,
+	})
+}
+
+func decodeCamera(properties []byte) (Component, error) {
+	p := &cameraProperties{
+		ClearColor:  ColorBlack(),
+		Fov:         1.309,
+		NearClip:    0.01,
+		FarClip:     100000.0,
+		CullingMask: LayerMaskAll,
+	}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	c := NewCamera(p.RenderPath, p.HDR)
+	c.clearMode = p.ClearMode
+	c.clearColor = p.ClearColor
+	c.fov = p.Fov
+	c.cullingMask = p.CullingMask
+	c.nearClip = p.NearClip
+	c.farClip = p.FarClip
+	c.orthographic = p.Orthographic
+	c.UpdateMatrices()
+
+	return c, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"time"
+)
+
+var _ Clock = &RealClock{}
+var _ Clock = &ManualClock{}
+
+// Clock is a source of time for the Time system.
+type Clock interface {
+	// Now returns the current time in seconds.
+	Now() float64
+}
+
+// RealClock is a Clock which follows the monotonic wall clock.
+type RealClock struct {
+	start time.Time
+}
+
+// Now returns the number of seconds since the clock was created.
+func (c *RealClock) Now() float64 {
+	return time.Since(c.start).Seconds()
+}
+
+// NewRealClock creates a new RealClock.
+func NewRealClock() *RealClock {
+	return &RealClock{
+		start: time.Now(),
+	}
+}
+
+// ManualClock is a Clock which only moves when advanced. It is used for
+// deterministic simulation and tests.
+type ManualClock struct {
+	now float64
+}
+
+// Now returns the current time of the clock in seconds.
+func (c *ManualClock) Now() float64 {
+	return c.now
+}
+
+// Advance moves the clock forward by the given number of seconds.
+func (c *ManualClock) Advance(seconds float64) {
+	c.now += seconds
+}
+
+// Set sets the current time of the clock in seconds.
+func (c *ManualClock) Set(now float64) {
+	c.now = now
+}
+
+// NewManualClock creates a new ManualClock starting at zero.
+func NewManualClock() *ManualClock {
+	return &ManualClock{}
+}
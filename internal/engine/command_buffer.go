@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// CommandType identifies a structural change queued in a CommandBuffer.
+type CommandType uint8
+
+const (
+	CommandSpawn CommandType = iota
+	CommandDestroy
+	CommandReparent
+	CommandActivate
+	CommandDeactivate
+	CommandRemoveComponent
+)
+
+// Command is a queued structural change to a scene graph.
+type Command struct {
+	Type      CommandType
+	Object    *GameObject
+	Parent    *GameObject
+	Component Component
+	KeepWorld bool
+}
+
+// CommandBuffer queues structural changes to a scene graph so that they can be
+// issued while the graph is being iterated. Queued commands are applied in
+// order when the buffer is flushed, which the App does once per frame after
+// rendering.
+type CommandBuffer struct {
+	graph    *SceneGraph
+	commands []Command
+}
+
+// Spawn queues adding the object and its descendants to the graph under the
+// given parent. A nil parent adds the object to the top level of the graph.
+func (b *CommandBuffer) Spawn(object, parent *GameObject) {
+	b.Push(Command{Type: CommandSpawn, Object: object, Parent: parent})
+}
+
+// Destroy queues removing and destroying the object and its descendants.
+func (b *CommandBuffer) Destroy(object *GameObject) {
+	b.Push(Command{Type: CommandDestroy, Object: object})
+}
+
+// SetParent queues moving the object under a new parent. See
+// SceneGraph.SetParent.
+func (b *CommandBuffer) SetParent(object, parent *GameObject, keepWorld bool) {
+	b.Push(Command{Type: CommandReparent, Object: object, Parent: parent, KeepWorld: keepWorld})
+}
+
+// SetActive queues activating or deactivating the object.
+func (b *CommandBuffer) SetActive(object *GameObject, active bool) {
+	if active {
+		b.Push(Command{Type: CommandActivate, Object: object})
+	} else {
+		b.Push(Command{Type: CommandDeactivate, Object: object})
+	}
+}
+
+// RemoveComponent queues removing and destroying a component of the object.
+func (b *CommandBuffer) RemoveComponent(object *GameObject, component Component) {
+	b.Push(Command{Type: CommandRemoveComponent, Object: object, Component: component})
+}
+
+// Push queues a command.
+func (b *CommandBuffer) Push(c Command) {
+	b.commands = append(b.commands, c)
+}
+
+// Len returns the number of queued commands.
+func (b *CommandBuffer) Len() int {
+	return len(b.commands)
+}
+
+// Flush applies all queued commands in order. Commands queued while flushing,
+// for example by Awake of a spawned object, are applied in the same flush.
+// Errors are logged and do not stop the remaining commands.
+func (b *CommandBuffer) Flush() {
+	for len(b.commands) > 0 {
+		commands := b.commands
+		b.commands = nil
+
+		for i := range commands {
+			if err := b.apply(commands[i]); err != nil {
+				logrus.Error("command buffer: ", err)
+			}
+		}
+	}
+
+	if b.graph.Dirty() {
+		b.graph.Update()
+	}
+}
+
+func (b *CommandBuffer) apply(c Command) error {
+	// Objects destroyed by an earlier command have been released.
+	if c.Object == nil || c.Object.ID() == 0 {
+		return nil
+	}
+
+	switch c.Type {
+	case CommandSpawn:
+		return b.graph.addGameObject(c.Object, c.Parent)
+	case CommandDestroy:
+		return b.graph.removeGameObject(c.Object)
+	case CommandReparent:
+		return b.graph.SetParent(c.Object, c.Parent, c.KeepWorld)
+	case CommandActivate:
+		c.Object.SetActive(true)
+	case CommandDeactivate:
+		c.Object.SetActive(false)
+	case CommandRemoveComponent:
+		c.Object.removeComponent(c.Component)
+	}
+
+	b.graph.SetDirty()
+
+	return nil
+}
+
+// NewCommandBuffer creates a new CommandBuffer for the scene graph.
+func NewCommandBuffer(graph *SceneGraph) *CommandBuffer {
+	return &CommandBuffer{
+		graph:    graph,
+		commands: []Command{},
+	}
+}
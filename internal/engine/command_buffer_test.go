@@ -0,0 +1,247 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+// setupTestApp sets up a headless App for a single test.
+func setupTestApp(t *testing.T) *App {
+	a := NewApp(&AppConfig{Name: "test", Headless: true})
+
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(a.Teardown)
+
+	return a
+}
+
+type testScript struct {
+	BaseScriptComponent
+
+	updates     int
+	fixed       int
+	awakes      int
+	activates   int
+	deactivates int
+	destroys    int
+	onUpdate    func()
+}
+
+func (c *testScript) FixedUpdate() {
+	c.fixed++
+}
+
+func (c *testScript) Awake() {
+	c.awakes++
+}
+
+func (c *testScript) OnActivate() {
+	c.activates++
+}
+
+func (c *testScript) OnDeactivate() {
+	c.deactivates++
+}
+
+func (c *testScript) OnDestroy() {
+	c.destroys++
+}
+
+func (c *testScript) Update() {
+	c.updates++
+
+	if c.onUpdate != nil {
+		c.onUpdate()
+	}
+}
+
+func newTestScript(onUpdate func()) *testScript {
+	c := &testScript{onUpdate: onUpdate}
+
+	c.SetName("TestScript")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func newTestScene(t *testing.T) *SceneGraph {
+	setupTestApp(t)
+
+	s := NewScene("test")
+	s.Setup()
+
+	return s.Graph()
+}
+
+func mustAdd(t *testing.T, s *SceneGraph, object, parent *GameObject) {
+	if err := s.AddGameObject(object, parent); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandBuffer_SpawnDuringUpdate(t *testing.T) {
+	s := newTestScene(t)
+
+	spawned := NewGameObject("spawned")
+	spawner := NewGameObject("spawner")
+	spawner.AddComponent(newTestScript(func() {
+		if err := s.AddGameObject(spawned, nil); err != nil {
+			t.Error(err)
+		}
+	}))
+	mustAdd(t, s, spawner, nil)
+
+	s.SendMessage(MessageUpdate)
+
+	if s.Find("/spawned") != nil {
+		t.Fatal("spawned object added during update")
+	}
+	if s.Commands().Len() != 1 {
+		t.Fatalf("queued commands: %d, expected 1", s.Commands().Len())
+	}
+
+	s.FlushCommands()
+
+	if s.Find("/spawned") != spawned {
+		t.Fatal("spawned object not added on flush")
+	}
+	if spawned.Scene() == nil {
+		t.Error("spawned object has no scene")
+	}
+	if len(s.FindByName("spawned")) != 1 {
+		t.Error("spawned object not active after flush")
+	}
+}
+
+func TestCommandBuffer_DestroyDuringUpdate(t *testing.T) {
+	s := newTestScene(t)
+
+	victim := NewGameObject("victim")
+	child := NewGameObject("child")
+	victim.AddChild(child)
+	child.SetParent(victim)
+	script := newTestScript(nil)
+	victim.AddComponent(script)
+
+	killer := NewGameObject("killer")
+	killer.AddComponent(newTestScript(func() {
+		victim.Destroy()
+	}))
+
+	mustAdd(t, s, killer, nil)
+	mustAdd(t, s, victim, nil)
+
+	s.SendMessage(MessageUpdate)
+
+	// Removal is deferred, so the victim still receives this update.
+	if script.updates != 1 {
+		t.Errorf("victim updates: %d, expected 1", script.updates)
+	}
+	if s.Find("/victim/child") != child {
+		t.Fatal("victim removed during update")
+	}
+
+	s.FlushCommands()
+
+	if s.Find("/victim") != nil {
+		t.Error("victim not removed on flush")
+	}
+	if !victim.Destroyed() || victim.ID() != 0 || child.ID() != 0 {
+		t.Error("victim hierarchy not released on flush")
+	}
+	if len(s.FindByName("killer")) != 1 {
+		t.Error("killer removed on flush")
+	}
+}
+
+func TestCommandBuffer_ReparentAndActivateDuringUpdate(t *testing.T) {
+	s := newTestScene(t)
+
+	a := NewGameObject("a")
+	b := NewGameObject("b")
+	driver := NewGameObject("driver")
+	driver.AddComponent(newTestScript(func() {
+		if err := s.SetParent(b, a, false); err != nil {
+			t.Error(err)
+		}
+		s.Commands().SetActive(a, false)
+	}))
+
+	mustAdd(t, s, driver, nil)
+	mustAdd(t, s, a, nil)
+	mustAdd(t, s, b, nil)
+
+	s.SendMessage(MessageUpdate)
+
+	if b.Parent() != s.Root() {
+		t.Fatal("object reparented during update")
+	}
+
+	s.FlushCommands()
+
+	if s.Find("/a/b") != b {
+		t.Error("object not reparented on flush")
+	}
+	if a.Active() {
+		t.Error("object not deactivated on flush")
+	}
+	if len(s.FindByName("b")) != 0 {
+		t.Error("child of deactivated object is still active")
+	}
+
+	s.Commands().SetActive(a, true)
+	s.FlushCommands()
+
+	if len(s.FindByName("b")) != 1 {
+		t.Error("child of reactivated object is not active")
+	}
+}
+
+func TestCommandBuffer_RemoveComponentDuringUpdate(t *testing.T) {
+	s := newTestScene(t)
+
+	g := NewGameObject("object")
+	victim := newTestScript(nil)
+	g.AddComponent(newTestScript(func() {
+		g.RemoveComponent(victim)
+	}))
+	g.AddComponent(victim)
+
+	mustAdd(t, s, g, nil)
+
+	s.SendMessage(MessageUpdate)
+
+	// Removal is deferred, so the component still receives this update.
+	if victim.updates != 1 || victim.destroys != 0 {
+		t.Errorf("victim updates: %d destroys: %d, expected 1 and 0", victim.updates, victim.destroys)
+	}
+
+	s.FlushCommands()
+
+	if victim.destroys != 1 || victim.ID() != 0 || len(g.Components()) != 2 {
+		t.Error("component not removed on flush")
+	}
+}
@@ -40,6 +40,14 @@ type Component interface {
 	Validate() error
 }
 
+// ComponentDestroyer is implemented by components which release resources
+// when they are destroyed.
+type ComponentDestroyer interface {
+	// OnDestroy is called when the GameObject of the component is destroyed
+	// or the component is removed, before its instance ID is released.
+	OnDestroy()
+}
+
 type ScriptComponent interface {
 	Component
 
@@ -55,6 +63,9 @@ type ScriptComponent interface {
 	// OnDeactivate is called when the component transitions to the inactive state.
 	OnDeactivate()
 
+	// OnDestroy is called when the GameObject of the component is destroyed,
+	// before its instance ID is released.
+	OnDestroy()
 
 	// Awake is called when the Entity is loaded by the scene graph. Note: it is
 	// not guaranteed that all parent and child associations are made. If such conditions
@@ -88,7 +99,7 @@ type BaseComponent struct {
 type BaseScriptComponent struct {
 	BaseComponent
 
-	active bool
+	inactive bool
 }
 
 // GameObject returns the GameObject for this component.
@@ -115,21 +126,23 @@ func (c *BaseComponent) Validate() error {
 	return nil
 }
 
-// Active returns the active state of this component.
+// Active returns the active state of this component. Components are active
+// by default.
 func (c *BaseScriptComponent) Active() bool {
-	return c.active
+	return !c.inactive
 }
 
-// SetActive sets the active state of this component.
+// SetActive sets the active state of this component. If the GameObject of the
+// component is active in a scene, OnActivate or OnDeactivate is called.
 func (c *BaseScriptComponent) SetActive(active bool) {
-	if c.active != active {
-		c.active = active
-
-		if c.active {
-			c.OnActivate()
-		} else {
-			c.OnDeactivate()
-		}
+	if c.Active() == active {
+		return
+	}
+
+	c.inactive = !active
+
+	if c.gameobject != nil {
+		c.gameobject.componentActiveChanged(c.ID(), active)
 	}
 }
 
@@ -139,6 +152,10 @@ func (c *BaseScriptComponent) OnActivate() {}
 // OnDeactivate is called when the component transitions to the inactive state.
 func (c *BaseScriptComponent) OnDeactivate() {}
 
+// OnDestroy is called when the GameObject of the component is destroyed,
+// before its instance ID is released.
+func (c *BaseScriptComponent) OnDestroy() {}
+
 // Awake is called when the Entity is loaded by the scene graph. Note: it is
 // not guaranteed that all parent and child associations are made. If such conditions
 // are required, use Start() instead.
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	componentTypes     = make(map[string]ComponentDecoder)
+	componentTypeNames = make(map[reflect.Type]string)
+	componentTypesMu   = &sync.RWMutex{}
+)
+
+// ErrComponentTypeNotRegistered reports that no component type has been
+// registered with the given name.
+type ErrComponentTypeNotRegistered string
+
+func (e ErrComponentTypeNotRegistered) Error() string {
+	return "component: type not registered: " + string(e)
+}
+
+// ComponentDecoder creates a new component from the properties stored in a
+// scene file. The properties are nil if none were saved.
+type ComponentDecoder func(properties []byte) (Component, error)
+
+// PropertyEncoder is implemented by components that have properties which
+// should be written to scene files. Components which do not implement this
+// interface are saved by type name only.
+type PropertyEncoder interface {
+	// EncodeProperties returns the JSON encoded properties of the component.
+	EncodeProperties() ([]byte, error)
+}
+
+// ComponentCloner is implemented by components which can be copied by
+// GameObject.Clone. Components which do not implement this interface cannot be
+// cloned.
+type ComponentCloner interface {
+	// CloneComponent returns a copy of the component with a fresh instance
+	// ID. The copy is not attached to a GameObject.
+	CloneComponent() (Component, error)
+}
+
+// RegisterComponentType registers a component type by name so that it can be
+// saved to and loaded from scene files. The prototype is only used to identify
+// the concrete type of the component. It is an error to register the same name
+// more than once.
+func RegisterComponentType(name string, prototype Component, decoder ComponentDecoder) {
+	componentTypesMu.Lock()
+	defer componentTypesMu.Unlock()
+
+	if _, dup := componentTypes[name]; dup {
+		panic(fmt.Sprintf("component: type %s registered twice", name))
+	}
+
+	componentTypes[name] = decoder
+	componentTypeNames[reflect.TypeOf(prototype)] = name
+}
+
+// ComponentTypeName returns the registered type name of the component.
+func ComponentTypeName(c Component) (string, bool) {
+	componentTypesMu.RLock()
+	defer componentTypesMu.RUnlock()
+
+	name, ok := componentTypeNames[reflect.TypeOf(c)]
+
+	return name, ok
+}
+
+// DecodeComponent creates a new component of the registered type name from
+// its serialized properties.
+func DecodeComponent(name string, properties []byte) (Component, error) {
+	componentTypesMu.RLock()
+	decoder, ok := componentTypes[name]
+	componentTypesMu.RUnlock()
+
+	if !ok {
+		return nil, ErrComponentTypeNotRegistered(name)
+	}
+
+	return decoder(properties)
+}
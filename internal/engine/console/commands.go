@@ -0,0 +1,422 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package console
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+const ErrNoMaterial = engine.Error("console: object has no material")
+
+type ErrSceneNotFound string
+type ErrObjectNotFound string
+type ErrInvalidValue string
+
+func (e ErrSceneNotFound) Error() string {
+	return "console: scene " + string(e) + " not found"
+}
+
+func (e ErrObjectNotFound) Error() string {
+	return "console: object " + string(e) + " not found"
+}
+
+func (e ErrInvalidValue) Error() string {
+	return "console: invalid value for " + string(e)
+}
+
+// materialRenderer is implemented by components which render with a material.
+type materialRenderer interface {
+	GetMaterial() *engine.Material
+}
+
+// target selects a GameObject by path in a scene. The active scene is used if
+// no scene is given.
+type target struct {
+	Scene string `json:"scene"`
+	Path  string `json:"path"`
+}
+
+// node is the tree representation of a GameObject.
+type node struct {
+	Name       string           `json:"name"`
+	ID         uint32           `json:"id"`
+	Active     bool             `json:"active"`
+	Tag        string           `json:"tag,omitempty"`
+	Layer      engine.LayerMask `json:"layer"`
+	Components []string         `json:"components,omitempty"`
+	Children   []*node          `json:"children,omitempty"`
+}
+
+type scenesResult struct {
+	Registered []string `json:"registered"`
+	Active     []string `json:"active"`
+}
+
+type treeResult struct {
+	Scene   string  `json:"scene"`
+	Objects []*node `json:"objects"`
+}
+
+type setTransformArgs struct {
+	target
+	Position *mgl32.Vec3 `json:"position"`
+	Rotation *mgl32.Vec4 `json:"rotation"`
+	Scale    *mgl32.Vec3 `json:"scale"`
+}
+
+type materialResult struct {
+	Shader     string                 `json:"shader"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type setMaterialArgs struct {
+	target
+	Property string          `json:"property"`
+	Value    json.RawMessage `json:"value"`
+}
+
+type setActiveArgs struct {
+	target
+	Active bool `json:"active"`
+}
+
+type sceneArgs struct {
+	Name string `json:"name"`
+}
+
+func registerCommands(s *Server) {
+	s.Handle("help", func(app *engine.App, args json.RawMessage) (interface{}, error) {
+		return s.Commands(), nil
+	})
+	s.Handle("scenes", cmdScenes)
+	s.Handle("tree", cmdTree)
+	s.Handle("get_transform", cmdGetTransform)
+	s.Handle("set_transform", cmdSetTransform)
+	s.Handle("get_material", cmdGetMaterial)
+	s.Handle("set_material", cmdSetMaterial)
+	s.Handle("set_active", cmdSetActive)
+	s.Handle("push_scene", cmdPushScene)
+	s.Handle("pop_scene", cmdPopScene)
+}
+
+func cmdScenes(app *engine.App, _ json.RawMessage) (interface{}, error) {
+	return &scenesResult{
+		Registered: app.SceneNames(),
+		Active:     app.ActiveSceneNames(),
+	}, nil
+}
+
+func cmdTree(app *engine.App, args json.RawMessage) (interface{}, error) {
+	t := &target{}
+	if err := decodeArgs(args, t); err != nil {
+		return nil, err
+	}
+
+	scene, err := findScene(app, t.Scene)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &treeResult{Scene: scene.Name(), Objects: []*node{}}
+	if scene.Graph() == nil {
+		return result, nil
+	}
+
+	children := scene.Graph().Root().Children()
+	for i := range children {
+		result.Objects = append(result.Objects, newNode(children[i]))
+	}
+
+	return result, nil
+}
+
+func cmdGetTransform(app *engine.App, args json.RawMessage) (interface{}, error) {
+	t := &target{}
+	if err := decodeArgs(args, t); err != nil {
+		return nil, err
+	}
+
+	g, err := findObject(app, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformMetadata(g.Transform()), nil
+}
+
+func cmdSetTransform(app *engine.App, args json.RawMessage) (interface{}, error) {
+	a := &setTransformArgs{}
+	if err := decodeArgs(args, a); err != nil {
+		return nil, err
+	}
+
+	g, err := findObject(app, &a.target)
+	if err != nil {
+		return nil, err
+	}
+
+	t := g.Transform()
+	if a.Position != nil {
+		t.SetPosition(*a.Position)
+	}
+	if a.Rotation != nil {
+		r := *a.Rotation
+		t.SetRotation(mgl32.Quat{W: r[0], V: mgl32.Vec3{r[1], r[2], r[3]}})
+	}
+	if a.Scale != nil {
+		t.SetScale(*a.Scale)
+	}
+
+	return transformMetadata(t), nil
+}
+
+func cmdGetMaterial(app *engine.App, args json.RawMessage) (interface{}, error) {
+	t := &target{}
+	if err := decodeArgs(args, t); err != nil {
+		return nil, err
+	}
+
+	m, err := findMaterial(app, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMaterialResult(m), nil
+}
+
+func cmdSetMaterial(app *engine.App, args json.RawMessage) (interface{}, error) {
+	a := &setMaterialArgs{}
+	if err := decodeArgs(args, a); err != nil {
+		return nil, err
+	}
+
+	m, err := findMaterial(app, &a.target)
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := m.Property(a.Property)
+
+	value, err := decodeValue(a.Value, current)
+	if err != nil {
+		return nil, ErrInvalidValue(a.Property)
+	}
+
+	m.SetProperty(a.Property, value)
+
+	return newMaterialResult(m), nil
+}
+
+func cmdSetActive(app *engine.App, args json.RawMessage) (interface{}, error) {
+	a := &setActiveArgs{}
+	if err := decodeArgs(args, a); err != nil {
+		return nil, err
+	}
+
+	g, err := findObject(app, &a.target)
+	if err != nil {
+		return nil, err
+	}
+
+	g.SetActive(a.Active)
+
+	return newNode(g), nil
+}
+
+func cmdPushScene(app *engine.App, args json.RawMessage) (interface{}, error) {
+	a := &sceneArgs{}
+	if err := decodeArgs(args, a); err != nil {
+		return nil, err
+	}
+
+	if err := app.PushScene(a.Name); err != nil {
+		return nil, err
+	}
+
+	return cmdScenes(app, nil)
+}
+
+func cmdPopScene(app *engine.App, _ json.RawMessage) (interface{}, error) {
+	return &sceneArgs{Name: app.PopScene()}, nil
+}
+
+func decodeArgs(args json.RawMessage, v interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(args, v)
+}
+
+// decodeValue converts a JSON value to a shader property value. Numbers keep
+// the type of the current value if it is int32 or uint32 and become float32
+// otherwise. Arrays of two to four numbers become vectors.
+func decodeValue(raw json.RawMessage, current interface{}) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		switch current.(type) {
+		case int32:
+			if v != math.Trunc(v) || v < math.MinInt32 || v > math.MaxInt32 {
+				return nil, ErrInvalidValue("int32")
+			}
+			return int32(v), nil
+		case uint32:
+			if v != math.Trunc(v) || v < 0 || v > math.MaxUint32 {
+				return nil, ErrInvalidValue("uint32")
+			}
+			return uint32(v), nil
+		}
+		return float32(v), nil
+	case []interface{}:
+		f := make([]float32, len(v))
+		for i := range v {
+			n, ok := v[i].(float64)
+			if !ok {
+				return nil, ErrInvalidValue("array element")
+			}
+			f[i] = float32(n)
+		}
+
+		switch len(f) {
+		case 2:
+			return mgl32.Vec2{f[0], f[1]}, nil
+		case 3:
+			return mgl32.Vec3{f[0], f[1], f[2]}, nil
+		case 4:
+			return mgl32.Vec4{f[0], f[1], f[2], f[3]}, nil
+		}
+	}
+
+	return nil, ErrInvalidValue("property")
+}
+
+func findScene(app *engine.App, name string) (*engine.Scene, error) {
+	if name == "" {
+		if scene := app.ActiveScene(); scene != nil {
+			return scene, nil
+		}
+		return nil, ErrSceneNotFound("(active)")
+	}
+
+	scene := app.Scene(name)
+	if scene == nil {
+		return nil, ErrSceneNotFound(name)
+	}
+
+	return scene, nil
+}
+
+func findObject(app *engine.App, t *target) (*engine.GameObject, error) {
+	scene, err := findScene(app, t.Scene)
+	if err != nil {
+		return nil, err
+	}
+	if scene.Graph() == nil {
+		return nil, ErrObjectNotFound(t.Path)
+	}
+
+	g := scene.Graph().Find(t.Path)
+	if g == nil {
+		return nil, ErrObjectNotFound(t.Path)
+	}
+
+	return g, nil
+}
+
+func findMaterial(app *engine.App, t *target) (*engine.Material, error) {
+	g, err := findObject(app, t)
+	if err != nil {
+		return nil, err
+	}
+
+	components := g.Components()
+	for i := range components {
+		if r, ok := components[i].(materialRenderer); ok && r.GetMaterial() != nil {
+			return r.GetMaterial(), nil
+		}
+	}
+
+	return nil, ErrNoMaterial
+}
+
+func newNode(g *engine.GameObject) *node {
+	n := &node{
+		Name:   g.Name(),
+		ID:     g.ID(),
+		Active: g.Active(),
+		Tag:    g.Tag(),
+		Layer:  g.Layer(),
+	}
+
+	components := g.Components()
+	for i := range components {
+		name, ok := engine.ComponentTypeName(components[i])
+		if !ok {
+			name = reflect.Indirect(reflect.ValueOf(components[i])).Type().Name()
+		}
+		n.Components = append(n.Components, name)
+	}
+
+	children := g.Children()
+	for i := range children {
+		n.Children = append(n.Children, newNode(children[i]))
+	}
+
+	return n
+}
+
+func newMaterialResult(m *engine.Material) *materialResult {
+	r := &materialResult{Properties: make(map[string]interface{})}
+
+	if m.Shader() != nil {
+		r.Shader = m.Shader().Name()
+	}
+	for _, name := range m.Properties() {
+		r.Properties[name], _ = m.Property(name)
+	}
+
+	return r
+}
+
+func transformMetadata(t engine.Transform) *engine.TransformMetadata {
+	r := t.Rotation()
+
+	return &engine.TransformMetadata{
+		Position: t.Position(),
+		Rotation: mgl32.Vec4{r.W, r.V[0], r.V[1], r.V[2]},
+		Scale:    t.Scale(),
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package console
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+var _ engine.System = &Server{}
+
+const SysNameConsole = "console"
+
+const (
+	ErrNotLocal      = engine.Error("console: address is not a loopback address")
+	ErrServerStopped = engine.Error("console: server stopped")
+)
+
+// maxLineSize is the maximum size of a request line.
+const maxLineSize = 1 << 20
+
+type ErrUnknownCommand string
+
+func (e ErrUnknownCommand) Error() string {
+	return "console: unknown command " + string(e)
+}
+
+// Request is a single console request. Requests are sent as one JSON object
+// per line.
+type Request struct {
+	ID      int             `json:"id"`
+	Command string          `json:"cmd"`
+	Args    json.RawMessage `json:"args,omitempty"`
+}
+
+// Response is the reply to a Request, sent as one JSON object per line.
+type Response struct {
+	ID     int         `json:"id"`
+	OK     bool        `json:"ok"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Handler runs a console command. Handlers run on the main thread between
+// frames, so they may freely access scenes and GameObjects.
+type Handler func(app *engine.App, args json.RawMessage) (interface{}, error)
+
+// Server implements a debug console system. It listens on a local TCP or Unix
+// socket and runs the commands it receives on the main thread of the App.
+type Server struct {
+	app      *engine.App
+	address  string
+	listener net.Listener
+	handlers map[string]Handler
+	conns    map[net.Conn]struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+// Setup sets up the System.
+func (s *Server) Setup() error {
+	l, err := listen(s.address)
+	if err != nil {
+		return err
+	}
+
+	s.listener = l
+	s.done = make(chan struct{})
+
+	logrus.Info("Debug console listening on ", l.Addr().Network(), ":", l.Addr())
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return nil
+}
+
+// Teardown tears down the System.
+func (s *Server) Teardown() {
+	if s.listener == nil {
+		return
+	}
+
+	close(s.done)
+	s.listener.Close()
+
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.listener = nil
+}
+
+// Name returns the name of the System.
+func (s *Server) Name() string {
+	return SysNameConsole
+}
+
+// Addr returns the address the server listens on, or nil if it is not set up.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+// Handle registers the handler for the command with the given name, replacing
+// any existing handler.
+func (s *Server) Handle(command string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[command] = handler
+}
+
+// Commands returns the names of all registered commands in sorted order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.handlers))
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				logrus.Error("console: ", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+
+		c.Close()
+	}()
+
+	scanner := bufio.NewScanner(c)
+	scanner.Buffer(make([]byte, 4096), maxLineSize)
+	encoder := json.NewEncoder(c)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		resp := s.handle(line)
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle decodes a request line and runs its command on the main thread.
+func (s *Server) handle(line string) *Response {
+	req := &Request{}
+	if err := json.Unmarshal([]byte(line), req); err != nil {
+		return &Response{Error: "console: invalid request: " + err.Error()}
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	ch := make(chan result, 1)
+	s.app.RunOnMain(func() {
+		value, err := s.execute(req)
+		ch <- result{value, err}
+	})
+
+	var r result
+	select {
+	case r = <-ch:
+	case <-s.done:
+		r.err = ErrServerStopped
+	}
+
+	resp := &Response{ID: req.ID, OK: r.err == nil, Result: r.value}
+	if r.err != nil {
+		resp.Error = r.err.Error()
+	}
+
+	return resp
+}
+
+// execute runs the command of the request. It must be called on the main
+// thread.
+func (s *Server) execute(req *Request) (interface{}, error) {
+	s.mu.Lock()
+	h, ok := s.handlers[req.Command]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, ErrUnknownCommand(req.Command)
+	}
+
+	return h(s.app, req.Args)
+}
+
+// listen listens on the given address. Addresses of the form "unix:path"
+// listen on a Unix socket, all others on TCP, optionally prefixed with "tcp:".
+// TCP addresses must be loopback addresses.
+func listen(address string) (net.Listener, error) {
+	if strings.HasPrefix(address, "unix:") {
+		return net.Listen("unix", strings.TrimPrefix(address, "unix:"))
+	}
+
+	address = strings.TrimPrefix(address, "tcp:")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, ErrNotLocal
+		}
+	}
+
+	return net.Listen("tcp", address)
+}
+
+// NewServer creates a new debug console server for the App, listening on the
+// given address once set up. The built-in commands are registered.
+func NewServer(app *engine.App, address string) *Server {
+	s := &Server{
+		app:      app,
+		address:  address,
+		handlers: make(map[string]Handler),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	registerCommands(s)
+
+	return s
+}
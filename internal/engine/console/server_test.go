@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package console
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+func setupConsole(t *testing.T) (*engine.App, *Server) {
+	a := engine.NewApp(&engine.AppConfig{Name: "test", Headless: true})
+	s := NewServer(a, "tcp:127.0.0.1:0")
+
+	a.SetPreSetup(func() error {
+		a.RegisterSystem(s)
+		return nil
+	})
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(a.Teardown)
+
+	scene := engine.NewScene("main")
+	scene.SetLoadFunc(func() error {
+		parent := engine.NewGameObject("parent")
+		if err := scene.Graph().AddGameObject(parent, nil); err != nil {
+			return err
+		}
+
+		return scene.Graph().AddGameObject(engine.NewGameObject("child"), parent)
+	})
+
+	if err := a.RegisterScene(scene); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RegisterScene(engine.NewScene("other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("main"); err != nil {
+		t.Fatal(err)
+	}
+
+	return a, s
+}
+
+// request sends a request line and runs frames until the response arrives.
+func request(t *testing.T, a *engine.App, c net.Conn, r *bufio.Reader, line string) *Response {
+	if _, err := io.WriteString(c, line+"\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan *Response, 1)
+	go func() {
+		resp := &Response{}
+		if b, err := r.ReadBytes('\n'); err == nil {
+			json.Unmarshal(b, resp)
+		}
+		ch <- resp
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case resp := <-ch:
+			return resp
+		default:
+			if err := a.RunTicks(1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Fatalf("no response to %s", line)
+	return nil
+}
+
+func TestServer_Commands(t *testing.T) {
+	a, s := setupConsole(t)
+
+	c, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	r := bufio.NewReader(c)
+
+	resp := request(t, a, c, r, `{"id":1,"cmd":"tree"}`)
+	if !resp.OK || resp.ID != 1 {
+		t.Fatalf("tree: %+v", resp)
+	}
+	objects := resp.Result.(map[string]interface{})["objects"].([]interface{})
+	if len(objects) != 1 || objects[0].(map[string]interface{})["name"] != "parent" {
+		t.Fatalf("tree: unexpected objects %v", objects)
+	}
+
+	resp = request(t, a, c, r, `{"id":2,"cmd":"set_transform","args":{"path":"parent/child","position":[1,2,3]}}`)
+	if !resp.OK {
+		t.Fatalf("set_transform: %+v", resp)
+	}
+	child := a.ActiveScene().Graph().Find("parent/child")
+	if child.Transform().Position()[1] != 2 {
+		t.Errorf("position not set: %v", child.Transform().Position())
+	}
+
+	resp = request(t, a, c, r, `{"id":3,"cmd":"set_active","args":{"path":"parent/child","active":false}}`)
+	if !resp.OK || child.Active() {
+		t.Errorf("set_active: %+v", resp)
+	}
+
+	resp = request(t, a, c, r, `{"id":4,"cmd":"push_scene","args":{"name":"other"}}`)
+	if !resp.OK || a.ActiveSceneName() != "other" {
+		t.Errorf("push_scene: %+v", resp)
+	}
+
+	resp = request(t, a, c, r, `{"id":5,"cmd":"pop_scene"}`)
+	if !resp.OK || a.ActiveSceneName() != "main" {
+		t.Errorf("pop_scene: %+v", resp)
+	}
+
+	resp = request(t, a, c, r, `{"id":6,"cmd":"get_transform","args":{"path":"missing"}}`)
+	if resp.OK || resp.Error == "" {
+		t.Errorf("get_transform of missing object: %+v", resp)
+	}
+
+	resp = request(t, a, c, r, `{"id":7,"cmd":"nope"}`)
+	if resp.OK {
+		t.Errorf("unknown command: %+v", resp)
+	}
+}
+
+func TestListen_NotLocal(t *testing.T) {
+	for _, address := range []string{"tcp:0.0.0.0:0", "192.0.2.1:7070", ":7070"} {
+		if _, err := listen(address); err != ErrNotLocal {
+			t.Errorf("%s: got %v, expected ErrNotLocal", address, err)
+		}
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		raw     string
+		current interface{}
+		want    interface{}
+		err     bool
+	}{
+		{`0.5`, float32(1), float32(0.5), false},
+		{`2`, nil, float32(2), false},
+		{`3`, int32(1), int32(3), false},
+		{`-3`, int32(1), int32(-3), false},
+		{`3.5`, int32(1), nil, true},
+		{`4`, uint32(1), uint32(4), false},
+		{`-4`, uint32(1), nil, true},
+		{`[1,2]`, int32(1), mgl32.Vec2{1, 2}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeValue(json.RawMessage(tt.raw), tt.current)
+		if (err != nil) != tt.err {
+			t.Errorf("%s (%T): error %v", tt.raw, tt.current, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) && !tt.err {
+			t.Errorf("%s (%T): got %#v, expected %#v", tt.raw, tt.current, got, tt.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ SOFTWARE.
 
 package engine
 
+import "reflect"
+
 type EffectType uint8
 
 const (
@@ -42,3 +44,8 @@ type Effect interface {
 	Render(EffectWriter)
 	Type() EffectType
 }
+
+// effectName returns the type name of the effect.
+func effectName(effect Effect) string {
+	return reflect.Indirect(reflect.ValueOf(effect)).Type().Name()
+}
@@ -56,5 +56,10 @@ func DefaultSkybox() *Skybox {
 }
 
 func DefaultShader() *Shader {
+	// Shaders are not loaded without an OpenGL context.
+	if !glAvailable() {
+		return nil
+	}
+
 	return GetAsset().MustGet(AssetNameShader, "standard").(*Shader)
 }
@@ -0,0 +1,244 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+)
+
+const (
+	ErrNotInScene   = Error("game object is not in a scene")
+	ErrNoGameObject = Error("component is not attached to a game object")
+)
+
+// EventBus delivers typed events to subscribers. Every Scene has a bus for
+// scene wide events, and every GameObject has a local bus used for events
+// targeted at that object and its ancestors. Events may be any type. The type
+// parameter of Publish or Emit selects the subscribers, so an event published
+// as an interface type only reaches subscribers of that interface type.
+type EventBus struct {
+	handlers map[reflect.Type][]*Subscription
+}
+
+// Subscription is a handle to a subscribed event handler.
+type Subscription struct {
+	bus       *EventBus
+	eventType reflect.Type
+	owner     Component
+	handler   func(interface{})
+}
+
+// Unsubscribe removes the handler from its event bus. It is safe to call
+// Unsubscribe more than once.
+func (s *Subscription) Unsubscribe() {
+	if s.bus != nil {
+		s.bus.remove(s)
+		s.bus = nil
+	}
+}
+
+// Owner returns the component owning this subscription, if any.
+func (s *Subscription) Owner() Component {
+	return s.owner
+}
+
+// expired reports if the owner of the subscription has been destroyed.
+func (s *Subscription) expired() bool {
+	return ownerExpired(s.owner)
+}
+
+// inactive reports if the owner of the subscription should not receive events.
+func (s *Subscription) inactive() bool {
+	return ownerInactive(s.owner)
+}
+
+// ownerExpired reports if the owner or its GameObject has been destroyed.
+// Destroyed and removed components are released and have no instance ID.
+func ownerExpired(owner Component) bool {
+	if owner == nil {
+		return false
+	}
+	if owner.ID() == 0 {
+		return true
+	}
+
+	g := owner.GameObject()
+
+	return g != nil && g.Destroyed()
+}
+
+// ownerInactive reports if the owner is disabled or its GameObject is not
+// active in the hierarchy.
+func ownerInactive(owner Component) bool {
+	if owner == nil {
+		return false
+	}
+
+	if c, ok := owner.(ScriptComponent); ok && !c.Active() {
+		return true
+	}
+
+	g := owner.GameObject()
+
+	return g != nil && !g.ActiveInHierarchy()
+}
+
+// Subscribe registers fn to be called for every event of type E published on
+// the bus. When owner is not nil, the handler is skipped while the owner or
+// its GameObject is inactive and removed when either is destroyed.
+func Subscribe[E any](bus *EventBus, owner Component, fn func(E)) *Subscription {
+	s := &Subscription{
+		bus:       bus,
+		eventType: reflect.TypeOf((*E)(nil)).Elem(),
+		owner:     owner,
+		handler: func(event interface{}) {
+			fn(event.(E))
+		},
+	}
+
+	bus.handlers[s.eventType] = append(bus.handlers[s.eventType], s)
+
+	return s
+}
+
+// Publish delivers the event to all handlers subscribed to type E on the bus.
+func Publish[E any](bus *EventBus, event E) {
+	bus.publish(reflect.TypeOf((*E)(nil)).Elem(), event)
+}
+
+// Emit delivers the event to handlers on the local bus of the game object.
+// If bubble is set, the event is then delivered to each ancestor in turn.
+func Emit[E any](g *GameObject, event E, bubble bool) {
+	eventType := reflect.TypeOf((*E)(nil)).Elem()
+
+	for o := g; o != nil; o = o.parent {
+		if o.events != nil {
+			o.events.publish(eventType, event)
+		}
+
+		if !bubble {
+			break
+		}
+	}
+}
+
+// SubscribeScene registers fn on the event bus of the scene owning the
+// component's GameObject.
+func SubscribeScene[E any](owner Component, fn func(E)) (*Subscription, error) {
+	g := owner.GameObject()
+	if g == nil || g.Scene() == nil {
+		return nil, ErrNotInScene
+	}
+
+	return Subscribe(g.Scene().Events(), owner, fn), nil
+}
+
+// SubscribeLocal registers fn on the local event bus of the component's
+// GameObject.
+func SubscribeLocal[E any](owner Component, fn func(E)) (*Subscription, error) {
+	g := owner.GameObject()
+	if g == nil {
+		return nil, ErrNoGameObject
+	}
+
+	return Subscribe(g.Events(), owner, fn), nil
+}
+
+func (b *EventBus) publish(eventType reflect.Type, event interface{}) {
+	// Copy so handlers may subscribe or unsubscribe while being called.
+	subscriptions := append([]*Subscription(nil), b.handlers[eventType]...)
+
+	for _, s := range subscriptions {
+		if s.bus != b {
+			continue
+		}
+		if s.expired() {
+			s.Unsubscribe()
+			continue
+		}
+		if s.inactive() {
+			continue
+		}
+
+		s.handler(event)
+	}
+}
+
+func (b *EventBus) remove(s *Subscription) {
+	list := b.handlers[s.eventType]
+
+	for i := range list {
+		if list[i] == s {
+			b.handlers[s.eventType] = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+
+	if len(b.handlers[s.eventType]) == 0 {
+		delete(b.handlers, s.eventType)
+	}
+}
+
+// removeExpired removes all subscriptions owned by destroyed objects and
+// components.
+func (b *EventBus) removeExpired() {
+	for _, list := range b.handlers {
+		for _, s := range append([]*Subscription(nil), list...) {
+			if s.expired() {
+				s.Unsubscribe()
+			}
+		}
+	}
+}
+
+// clear removes all subscriptions from the bus.
+func (b *EventBus) clear() {
+	for _, list := range b.handlers {
+		for i := range list {
+			list[i].bus = nil
+		}
+	}
+
+	b.handlers = make(map[reflect.Type][]*Subscription)
+}
+
+// Events returns the local event bus of this game object.
+func (g *GameObject) Events() *EventBus {
+	if g.events == nil {
+		g.events = NewEventBus()
+	}
+
+	return g.events
+}
+
+// Events returns the scene wide event bus.
+func (s *Scene) Events() *EventBus {
+	return s.events
+}
+
+// NewEventBus creates a new EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]*Subscription),
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+type testEvent struct {
+	value int
+}
+
+type testNamedEvent interface {
+	eventName() string
+}
+
+func (e testEvent) eventName() string {
+	return "test"
+}
+
+func handlerCount(b *EventBus) int {
+	n := 0
+	for _, list := range b.handlers {
+		n += len(list)
+	}
+
+	return n
+}
+
+func TestEventBus_Publish(t *testing.T) {
+	bus := NewEventBus()
+
+	var values []int
+	sub := Subscribe(bus, nil, func(e testEvent) {
+		values = append(values, e.value)
+	})
+
+	names := 0
+	Subscribe(bus, nil, func(e testNamedEvent) {
+		names++
+	})
+
+	Publish(bus, testEvent{1})
+	Publish(bus, testEvent{2})
+
+	// The type parameter selects the subscribers, not the dynamic type.
+	Publish[testNamedEvent](bus, testEvent{3})
+
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("values %v, expected [1 2]", values)
+	}
+	if names != 1 {
+		t.Errorf("interface subscriber called %d times, expected 1", names)
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	Publish(bus, testEvent{4})
+
+	if len(values) != 2 {
+		t.Errorf("values %v after unsubscribe", values)
+	}
+	if handlerCount(bus) != 1 {
+		t.Errorf("%d handlers, expected 1", handlerCount(bus))
+	}
+}
+
+func TestEventBus_Owner(t *testing.T) {
+	s := newTestScene(t)
+
+	g := NewGameObject("listener")
+	owner := newTestScript(nil)
+	g.AddComponent(owner)
+
+	if _, err := SubscribeScene(owner, func(testEvent) {}); err != ErrNotInScene {
+		t.Errorf("error %v, expected ErrNotInScene", err)
+	}
+	if _, err := SubscribeLocal(newTestScript(nil), func(testEvent) {}); err != ErrNoGameObject {
+		t.Errorf("error %v, expected ErrNoGameObject", err)
+	}
+
+	mustAdd(t, s, g, nil)
+
+	calls := 0
+	sub, err := SubscribeScene(owner, func(testEvent) { calls++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Owner() != owner {
+		t.Error("subscription owner not set")
+	}
+
+	bus := s.scene.Events()
+	Publish(bus, testEvent{})
+
+	// Inactive owners are skipped, but keep their subscription.
+	owner.SetActive(false)
+	Publish(bus, testEvent{})
+	owner.SetActive(true)
+	g.SetActive(false)
+	Publish(bus, testEvent{})
+	g.SetActive(true)
+	Publish(bus, testEvent{})
+
+	if calls != 2 {
+		t.Errorf("handler called %d times, expected 2", calls)
+	}
+	if handlerCount(bus) != 1 {
+		t.Errorf("%d handlers, expected 1", handlerCount(bus))
+	}
+}
+
+func TestEventBus_Emit(t *testing.T) {
+	s := newTestScene(t)
+
+	parent := NewGameObject("parent")
+	child := NewGameObject("child")
+	parent.AddChild(child)
+	child.SetParent(parent)
+
+	parentScript := newTestScript(nil)
+	childScript := newTestScript(nil)
+	parent.AddComponent(parentScript)
+	child.AddComponent(childScript)
+
+	mustAdd(t, s, parent, nil)
+
+	var received []string
+	for _, c := range []*testScript{parentScript, childScript} {
+		name := c.GameObject().Name()
+		if _, err := SubscribeLocal(c, func(testEvent) {
+			received = append(received, name)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Emit(child, testEvent{}, false)
+
+	if len(received) != 1 || received[0] != "child" {
+		t.Errorf("received %v, expected [child]", received)
+	}
+
+	received = nil
+	Emit(child, testEvent{}, true)
+
+	if len(received) != 2 || received[0] != "child" || received[1] != "parent" {
+		t.Errorf("received %v, expected [child parent]", received)
+	}
+
+	received = nil
+	Emit(parent, testEvent{}, true)
+
+	if len(received) != 1 || received[0] != "parent" {
+		t.Errorf("received %v, expected [parent]", received)
+	}
+}
+
+func TestEventBus_Cleanup(t *testing.T) {
+	s := newTestScene(t)
+	bus := s.scene.Events()
+
+	destroyed := NewGameObject("destroyed")
+	destroyedScript := newTestScript(nil)
+	destroyed.AddComponent(destroyedScript)
+
+	removed := NewGameObject("removed")
+	removedScript := newTestScript(nil)
+	removed.AddComponent(removedScript)
+
+	mustAdd(t, s, destroyed, nil)
+	mustAdd(t, s, removed, nil)
+
+	calls := 0
+	for _, c := range []*testScript{destroyedScript, removedScript} {
+		if _, err := SubscribeScene(c, func(testEvent) { calls++ }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := SubscribeLocal(removedScript, func(testEvent) { calls++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	// Subscriptions are removed with their owner's GameObject.
+	destroyed.Destroy()
+	s.FlushCommands()
+
+	if handlerCount(bus) != 1 {
+		t.Errorf("%d scene handlers after destroy, expected 1", handlerCount(bus))
+	}
+
+	// And with the owner itself, while its GameObject lives on.
+	removed.RemoveComponent(removedScript)
+
+	if handlerCount(bus) != 0 || handlerCount(removed.Events()) != 0 {
+		t.Errorf("%d scene and %d local handlers after remove, expected none", handlerCount(bus), handlerCount(removed.Events()))
+	}
+	if removedScript.destroys != 1 || removedScript.deactivates != 1 || GetComponent[*testScript](removed) != nil {
+		t.Error("removed component not destroyed")
+	}
+
+	Publish(bus, testEvent{})
+	Emit(removed, testEvent{}, false)
+
+	if calls != 0 {
+		t.Errorf("handlers called %d times, expected 0", calls)
+	}
+}
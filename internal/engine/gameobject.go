@@ -42,20 +42,49 @@ type GameObject struct {
 	children   []*GameObject
 	parent     *GameObject
 	scene      *Scene
+	prefab     *PrefabLink
+	events     *EventBus
+	tag        string
+	layer      LayerMask
 	active     bool
+	awake      bool
+	live       bool
+	destroyed  bool
 }
 
-// Active returns the active state of this game object.
+// Active returns the local active state of this game object. An object only
+// takes part in the scene if it and all of its ancestors are active, see
+// ActiveInHierarchy.
 func (g *GameObject) Active() bool {
 	return g.active
 }
 
-// SetActive sets the active state of this game object.
+// ActiveInHierarchy reports if this game object and all of its ancestors are
+// active.
+func (g *GameObject) ActiveInHierarchy() bool {
+	for o := g; o != nil; o = o.parent {
+		if !o.active {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetActive sets the local active state of this game object. If the object is
+// part of a scene, OnActivate or OnDeactivate is called on the active script
+// components of every object in the subtree whose ActiveInHierarchy state
+// changes, and the scene graph is marked dirty.
 func (g *GameObject) SetActive(active bool) {
-	if g.active != active {
-		g.active = active
+	if g.active == active {
+		return
+	}
+
+	g.active = active
+	g.refreshActive()
 
-		// TODO: Notify scene graph
+	if g.scene != nil && g.scene.Graph() != nil {
+		g.scene.Graph().SetDirty()
 	}
 }
 
@@ -80,17 +109,23 @@ func (g *GameObject) SetTransform(transform Transform) {
 
 // SendMessage calls the function associated with the given message.
 func (g *GameObject) SendMessage(msg Message) {
-	if !g.active {
+	if msg == MessageActivate {
+		g.activate()
 		return
 	}
 
-	if msg == MessageActivate {
-		g.activate()
-		g.SendMessage(MessageAwake)
+	if !g.active {
 		return
 	}
 
 	for i := range g.components {
+		// Disabled script components only receive lifecycle messages.
+		if c, ok := g.components[i].(ScriptComponent); ok && !c.Active() {
+			if msg != MessageAwake && msg != MessageSGUpdate {
+				continue
+			}
+		}
+
 		switch msg {
 		case MessageStart:
 			if c, ok := g.components[i].(ScriptComponent); ok {
@@ -120,7 +155,8 @@ func (g *GameObject) SendMessage(msg Message) {
 	}
 }
 
-// SetParent sets the parent of this game object.
+// SetParent sets the parent of this game object. This only updates the cached
+// parent reference, use SceneGraph.SetParent to move an object within a scene.
 func (g *GameObject) SetParent(object *GameObject) {
 	if object == nil {
 		return
@@ -191,8 +227,80 @@ func (g *GameObject) AddComponent(component Component) {
 		return
 	}
 
-	g.components = append(g.components, component)
+	g.insertComponent(len(g.components), component)
+}
+
+// insertComponent adds the component at index i of the component list.
+func (g *GameObject) insertComponent(i int, component Component) {
+	g.components = append(g.components, nil)
+	copy(g.components[i+1:], g.components[i:])
+	g.components[i] = component
 	component.SetGameObject(g)
+
+	// The component and type caches are rebuilt on the next graph update.
+	if g.scene != nil && g.scene.Graph() != nil {
+		g.scene.Graph().SetDirty()
+	}
+
+	if c, ok := component.(ScriptComponent); ok && g.live && c.Active() {
+		c.OnActivate()
+	}
+}
+
+// RemoveComponent removes the component from this game object and destroys
+// it. The transform cannot be removed. If the game object is part of a scene,
+// removal is queued in the scene graph command buffer while messages are being
+// dispatched.
+func (g *GameObject) RemoveComponent(component Component) {
+	if g.scene != nil && g.scene.Graph() != nil && g.scene.Graph().dispatching > 0 {
+		g.scene.Graph().Commands().RemoveComponent(g, component)
+		return
+	}
+
+	g.removeComponent(component)
+}
+
+// replaceComponent destroys the component and puts c in its place. Both go
+// through the same lifecycle as RemoveComponent and AddComponent.
+func (g *GameObject) replaceComponent(old, c Component) {
+	for i := 1; i < len(g.components); i++ {
+		if g.components[i] == old {
+			g.removeComponent(old)
+			g.insertComponent(i, c)
+			return
+		}
+	}
+}
+
+// removeComponent detaches and destroys the component, then removes the event
+// subscriptions and scheduler tasks it owned.
+func (g *GameObject) removeComponent(component Component) {
+	for i := 1; i < len(g.components); i++ {
+		if g.components[i] != component {
+			continue
+		}
+
+		if c, ok := component.(ScriptComponent); ok && g.live && c.Active() {
+			c.OnDeactivate()
+		}
+		if c, ok := component.(ComponentDestroyer); ok {
+			c.OnDestroy()
+		}
+
+		g.components = append(g.components[:i], g.components[i+1:]...)
+		releaseObjects(component)
+
+		if g.events != nil {
+			g.events.removeExpired()
+		}
+		if g.scene != nil {
+			g.scene.Events().removeExpired()
+			g.scene.Scheduler().removeExpired()
+			g.scene.Graph().SetDirty()
+		}
+
+		return
+	}
 }
 
 // AddChild adds a child game object to this game object.
@@ -206,31 +314,170 @@ func (g *GameObject) AddChild(child *GameObject) {
 	g.children = append(g.children, child)
 }
 
-// RemoveChild removes a child game object from this game object by ID.
+// RemoveChild removes a child game object from this game object by ID. The
+// child is detached but not destroyed. If this game object is part of a scene,
+// the child and its descendants are removed from the scene graph and may be
+// added again later.
 func (g *GameObject) RemoveChild(id uint32) {
-	// TODO: break all underlying associations
 	for i := range g.children {
 		if g.children[i].ID() == id {
+			child := g.children[i]
+
+			if g.scene != nil && child.scene == g.scene {
+				g.scene.Graph().detach(child)
+			}
+
+			g.removeChild(child)
+			child.OnParentChanged()
+			return
+		}
+	}
+}
+
+// RemoveAllChildren removes all child objects from this game object.
+func (g *GameObject) RemoveAllChildren() {
+	for len(g.children) > 0 {
+		g.RemoveChild(g.children[len(g.children)-1].ID())
+	}
+}
+
+// Destroy destroys this game object and its descendants. If the game object is
+// part of a scene, removal is queued in the scene graph command buffer so that
+// it is safe to call from within Update. Otherwise it is destroyed immediately.
+func (g *GameObject) Destroy() {
+	if g.destroyed {
+		return
+	}
+
+	g.destroyed = true
+
+	if g.scene != nil && g.scene.Graph() != nil {
+		g.scene.Graph().Commands().Destroy(g)
+		return
+	}
+
+	if g.parent != nil {
+		g.parent.removeChild(g)
+	}
+
+	destroyGameObjects(g.hierarchy())
+}
+
+// Destroyed reports if Destroy has been called on this game object.
+func (g *GameObject) Destroyed() bool {
+	return g.destroyed
+}
+
+// removeChild removes the child from the children of this game object and
+// clears its parent.
+func (g *GameObject) removeChild(child *GameObject) {
+	for i := range g.children {
+		if g.children[i] == child {
 			g.children[i] = g.children[len(g.children)-1]
 			g.children = g.children[:len(g.children)-1]
+			break
 		}
 	}
+
+	if child.parent == g {
+		child.parent = nil
+	}
 }
 
-// AddChild removes all child objects from this game object.
-func (g *GameObject) RemoveAllChildren() {
-	// TODO: Add me
-	panic(ErrNotImplemented)
+// hierarchy lists this game object and all of its descendants, parents before
+// children.
+func (g *GameObject) hierarchy() []*GameObject {
+	objects := []*GameObject{g}
+
+	for i := range g.children {
+		objects = append(objects, g.children[i].hierarchy()...)
+	}
+
+	return objects
 }
 
 // activate is called when the game object is initialized and needs to build
 // associations between itself and other objects and components. This typically
-// occurs when an object is added to a scene graph.
+// occurs when an object is added to a scene graph. If the object is active in
+// the hierarchy, it is awoken the first time and its components are activated.
 func (g *GameObject) activate() {
 	// Update component references.
 	for i := range g.components {
 		g.components[i].SetGameObject(g)
 	}
+
+	if !g.ActiveInHierarchy() {
+		return
+	}
+
+	if !g.awake {
+		g.awake = true
+		g.SendMessage(MessageAwake)
+	}
+
+	if !g.live {
+		g.setLive(true)
+	}
+}
+
+// refreshActive brings the activation state of the subtree in line with
+// ActiveInHierarchy. Objects are deactivated children first and activated
+// parents first. Objects which have not been awoken by a scene graph yet are
+// activated when they are added to one.
+func (g *GameObject) refreshActive() {
+	objects := g.hierarchy()
+
+	for i := len(objects) - 1; i >= 0; i-- {
+		if objects[i].live && !objects[i].ActiveInHierarchy() {
+			objects[i].setLive(false)
+		}
+	}
+
+	for i := range objects {
+		if objects[i].scene != nil && !objects[i].live && objects[i].ActiveInHierarchy() {
+			objects[i].activate()
+		}
+	}
+}
+
+// setLive calls OnActivate or OnDeactivate on the active script components of
+// this game object.
+func (g *GameObject) setLive(live bool) {
+	g.live = live
+
+	for i := range g.components {
+		if c, ok := g.components[i].(ScriptComponent); ok && c.Active() {
+			if live {
+				c.OnActivate()
+			} else {
+				c.OnDeactivate()
+			}
+		}
+	}
+}
+
+// componentActiveChanged is called by script components when their own active
+// state changes.
+func (g *GameObject) componentActiveChanged(id uint32, active bool) {
+	if !g.live {
+		return
+	}
+
+	for i := range g.components {
+		if g.components[i].ID() != id {
+			continue
+		}
+
+		if c, ok := g.components[i].(ScriptComponent); ok {
+			if active {
+				c.OnActivate()
+			} else {
+				c.OnDeactivate()
+			}
+		}
+
+		return
+	}
 }
 
 // SetScene sets the scene for this game object. Once set, the scene cannot be
@@ -246,6 +493,16 @@ func (g *GameObject) Scene() *Scene {
 	return g.scene
 }
 
+// App returns the App of the scene the GameObject is in. GameObjects which are
+// not in a scene use the current App.
+func (g *GameObject) App() *App {
+	if g.scene != nil {
+		return g.scene.App()
+	}
+
+	return CurrentApp()
+}
+
 // Ancestors lists all ancestor objects of this game object.
 func (g *GameObject) Ancestors() []*GameObject {
 	ancestors := []*GameObject{}
@@ -263,10 +520,125 @@ func (g *GameObject) OnParentChanged() {
 	g.Transform().Recompute(true)
 }
 
+// Prefab returns the prefab link of this game object, or nil if it is not the
+// root of a prefab instance.
+func (g *GameObject) Prefab() *PrefabLink {
+	return g.prefab
+}
+
+// Clone creates a deep copy of this game object, its components and its
+// children. Every copied object and component is assigned a fresh instance ID.
+// The clone is not added to a scene graph and has no parent. All components
+// must implement ComponentCloner.
+func (g *GameObject) Clone() (*GameObject, error) {
+	c := NewGameObject(g.Name())
+	c.tag = g.tag
+	c.layer = g.layer
+
+	t := c.Transform()
+	t.SetScale(g.Transform().Scale())
+	t.SetRotation(g.Transform().Rotation())
+	t.SetPosition(g.Transform().Position())
+
+	for i := 1; i < len(g.components); i++ {
+		component, err := cloneComponent(g.components[i])
+		if err != nil {
+			c.release()
+			return nil, err
+		}
+
+		c.AddComponent(component)
+	}
+
+	for i := range g.children {
+		child, err := g.children[i].Clone()
+		if err != nil {
+			c.release()
+			return nil, err
+		}
+
+		c.AddChild(child)
+		child.SetParent(c)
+		child.OnParentChanged()
+	}
+
+	if g.prefab != nil {
+		c.prefab = g.prefab.clone()
+	}
+
+	c.SetActive(g.active)
+
+	return c, nil
+}
+
+// release releases the instance IDs of this detached hierarchy without
+// running destroy hooks. It cleans up objects which failed to be built.
+func (g *GameObject) release() {
+	for i := range g.children {
+		g.children[i].release()
+	}
+
+	for i := range g.components {
+		releaseObjects(g.components[i])
+	}
+	releaseObjects(g)
+}
+
+// destroyGameObjects calls the destroy hooks on the components of the objects
+// and releases their instance IDs through the instance systems which assigned
+// them. Objects are torn down children first.
+func destroyGameObjects(objects []*GameObject) {
+	released := []Object{}
+
+	for i := len(objects) - 1; i >= 0; i-- {
+		g := objects[i]
+		g.destroyed = true
+
+		if g.live {
+			g.setLive(false)
+		}
+
+		for j := range g.components {
+			if c, ok := g.components[j].(ComponentDestroyer); ok {
+				c.OnDestroy()
+			}
+		}
+	}
+
+	scenes := make(map[*Scene]struct{})
+
+	for i := range objects {
+		if objects[i].scene != nil {
+			scenes[objects[i].scene] = struct{}{}
+		}
+		if objects[i].events != nil {
+			objects[i].events.clear()
+			objects[i].events = nil
+		}
+
+		for j := range objects[i].components {
+			released = append(released, objects[i].components[j])
+		}
+		released = append(released, objects[i])
+
+		objects[i].children = objects[i].children[:0]
+		objects[i].parent = nil
+		objects[i].scene = nil
+	}
+
+	for scene := range scenes {
+		scene.Events().removeExpired()
+		scene.Scheduler().removeExpired()
+	}
+
+	releaseObjects(released...)
+}
+
 // NewGameObject creates a new GameObject.
 func NewGameObject(name string) *GameObject {
 	g := &GameObject{
 		active:     true,
+		layer:      LayerDefault,
 		components: make([]Component, 1),
 		children:   []*GameObject{},
 	}
@@ -0,0 +1,284 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+func TestGameObject_SetActivePropagation(t *testing.T) {
+	s := newTestScene(t)
+
+	parent := NewGameObject("parent")
+	child := NewGameObject("child")
+	parent.AddChild(child)
+	child.SetParent(parent)
+
+	parentScript := newTestScript(nil)
+	childScript := newTestScript(nil)
+	parent.AddComponent(parentScript)
+	child.AddComponent(childScript)
+
+	mustAdd(t, s, parent, nil)
+
+	if childScript.awakes != 1 || childScript.activates != 1 {
+		t.Fatalf("child awakes: %d activates: %d, expected 1 and 1", childScript.awakes, childScript.activates)
+	}
+
+	parent.SetActive(false)
+
+	if !s.Dirty() {
+		t.Error("scene graph not marked dirty")
+	}
+	if parentScript.deactivates != 1 || childScript.deactivates != 1 {
+		t.Errorf("deactivates: %d %d, expected 1 and 1", parentScript.deactivates, childScript.deactivates)
+	}
+	if !child.Active() || child.ActiveInHierarchy() {
+		t.Error("child should be active but not active in hierarchy")
+	}
+
+	// Toggling a child below an inactive parent does not change its state
+	// in the hierarchy.
+	child.SetActive(false)
+	child.SetActive(true)
+
+	if childScript.activates != 1 || childScript.deactivates != 1 {
+		t.Errorf("child activates: %d deactivates: %d, expected 1 and 1", childScript.activates, childScript.deactivates)
+	}
+
+	parent.SetActive(true)
+
+	if parentScript.activates != 2 || childScript.activates != 2 {
+		t.Errorf("activates: %d %d, expected 2 and 2", parentScript.activates, childScript.activates)
+	}
+	if childScript.awakes != 1 {
+		t.Errorf("child awakes: %d, expected 1", childScript.awakes)
+	}
+}
+
+func TestGameObject_AwakeWhenFirstActive(t *testing.T) {
+	s := newTestScene(t)
+
+	object := NewGameObject("object")
+	script := newTestScript(nil)
+	object.AddComponent(script)
+	object.SetActive(false)
+
+	mustAdd(t, s, object, nil)
+
+	if script.awakes != 0 || script.activates != 0 {
+		t.Fatal("inactive object awoken when added")
+	}
+
+	object.SetActive(true)
+
+	if script.awakes != 1 || script.activates != 1 {
+		t.Errorf("awakes: %d activates: %d, expected 1 and 1", script.awakes, script.activates)
+	}
+}
+
+func TestGameObject_ComponentSetActive(t *testing.T) {
+	s := newTestScene(t)
+
+	object := NewGameObject("object")
+	script := newTestScript(nil)
+	object.AddComponent(script)
+	mustAdd(t, s, object, nil)
+
+	script.SetActive(false)
+	s.SendMessage(MessageUpdate)
+
+	if script.deactivates != 1 {
+		t.Errorf("deactivates: %d, expected 1", script.deactivates)
+	}
+	if script.updates != 0 {
+		t.Error("inactive component received update")
+	}
+
+	script.SetActive(true)
+	s.SendMessage(MessageUpdate)
+
+	if script.activates != 2 {
+		t.Errorf("activates: %d, expected 2", script.activates)
+	}
+	if script.updates != 1 {
+		t.Errorf("updates: %d, expected 1", script.updates)
+	}
+
+	object.Destroy()
+	s.FlushCommands()
+
+	if script.deactivates != 2 {
+		t.Errorf("deactivates after destroy: %d, expected 2", script.deactivates)
+	}
+}
+
+func TestGameObject_Destroy(t *testing.T) {
+	s := newTestScene(t)
+
+	parent := NewGameObject("parent")
+	child := NewGameObject("child")
+	parent.AddChild(child)
+	child.SetParent(parent)
+
+	parentScript := newTestScript(nil)
+	childScript := newTestScript(nil)
+	parent.AddComponent(parentScript)
+	child.AddComponent(childScript)
+
+	mustAdd(t, s, parent, nil)
+
+	ids := []uint32{parent.ID(), child.ID(), parentScript.ID(), childScript.ID(), parent.Transform().ID()}
+
+	parent.Destroy()
+	parent.Destroy()
+	s.FlushCommands()
+	parent.Destroy()
+	child.Destroy()
+
+	if s.Find("/parent") != nil || s.Find("/parent/child") != nil {
+		t.Error("hierarchy not removed from the scene graph")
+	}
+	if child.Parent() != nil || len(parent.Children()) != 0 || child.Scene() != nil {
+		t.Error("hierarchy not detached")
+	}
+	for _, id := range ids {
+		if _, err := GetInstance().Get(id); err == nil {
+			t.Errorf("id %08X not released", id)
+		}
+	}
+	for _, c := range []*testScript{parentScript, childScript} {
+		if c.destroys != 1 || c.deactivates != 1 {
+			t.Errorf("destroys: %d deactivates: %d, expected 1 and 1", c.destroys, c.deactivates)
+		}
+	}
+}
+
+type testDestroyer struct {
+	BaseComponent
+
+	destroys int
+}
+
+func (c *testDestroyer) OnDestroy() {
+	c.destroys++
+}
+
+func newTestDestroyer() *testDestroyer {
+	c := &testDestroyer{}
+
+	c.SetName("TestDestroyer")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func TestGameObject_DestroyComponentDestroyer(t *testing.T) {
+	s := newTestScene(t)
+
+	g := NewGameObject("destroyer")
+	destroyed := newTestDestroyer()
+	removed := newTestDestroyer()
+	g.AddComponent(destroyed)
+	g.AddComponent(removed)
+	mustAdd(t, s, g, nil)
+
+	g.RemoveComponent(removed)
+	if removed.destroys != 1 {
+		t.Errorf("removed component destroys: %d, expected 1", removed.destroys)
+	}
+
+	g.Destroy()
+	s.FlushCommands()
+
+	if destroyed.destroys != 1 || removed.destroys != 1 {
+		t.Errorf("destroys: %d and %d, expected 1 and 1", destroyed.destroys, removed.destroys)
+	}
+}
+
+func TestGameObject_DestroyDetached(t *testing.T) {
+	setupTestApp(t)
+
+	root := NewGameObject("root")
+	child := NewGameObject("child")
+	root.AddChild(child)
+	child.SetParent(root)
+
+	script := newTestScript(nil)
+	child.AddComponent(script)
+
+	child.Destroy()
+
+	if len(root.Children()) != 0 || child.Parent() != nil {
+		t.Error("child not removed from its parent")
+	}
+	if !child.Destroyed() || child.ID() != 0 || script.ID() != 0 {
+		t.Error("child not released")
+	}
+	if root.Destroyed() || root.ID() == 0 {
+		t.Error("parent destroyed with its child")
+	}
+	// The child was never live, so it is destroyed without deactivating.
+	if script.destroys != 1 || script.deactivates != 0 {
+		t.Errorf("destroys: %d deactivates: %d, expected 1 and 0", script.destroys, script.deactivates)
+	}
+}
+
+func TestGameObject_RemoveChild(t *testing.T) {
+	s := newTestScene(t)
+
+	parent := NewGameObject("parent")
+	a := NewGameObject("a")
+	b := NewGameObject("b")
+	for _, c := range []*GameObject{a, b} {
+		parent.AddChild(c)
+		c.SetParent(parent)
+	}
+
+	script := newTestScript(nil)
+	a.AddComponent(script)
+
+	mustAdd(t, s, parent, nil)
+
+	parent.RemoveChild(a.ID())
+
+	if a.Parent() != nil || a.Scene() != nil || len(parent.Children()) != 1 {
+		t.Error("child not detached")
+	}
+	if s.Find("/parent/a") != nil || s.Find("/parent/b") != b {
+		t.Error("scene graph not updated")
+	}
+	// Removed children are deactivated, but not destroyed.
+	if a.Destroyed() || a.ID() == 0 || script.deactivates != 1 || script.destroys != 0 {
+		t.Errorf("removed child destroyed: deactivates %d destroys %d", script.deactivates, script.destroys)
+	}
+
+	parent.RemoveAllChildren()
+
+	if len(parent.Children()) != 0 || b.Parent() != nil || s.Find("/parent/b") != nil {
+		t.Error("children not removed")
+	}
+	if s.Find("/parent") != parent {
+		t.Error("parent removed with its children")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"math"
 	"sync"
+	"sync/atomic"
 )
 
 var _ System = &Instance{}
@@ -43,20 +44,30 @@ type ErrIDAlreadyAssigned uint32
 type ErrIDNotFound uint32
 
 func (e ErrIDAlreadyAssigned) Error() string {
-	return fmt.Sprintf("object with ID %08X already assigned", e)
+	return fmt.Sprintf("object with ID %08X already assigned", uint32(e))
 }
 
 func (e ErrIDNotFound) Error() string {
-	return fmt.Sprintf("object with ID %08X not found", e)
+	return fmt.Sprintf("object with ID %08X not found", uint32(e))
 }
 
 // Instance implements a resource tracking system.
 type Instance struct {
 	objects map[uint32]Object
-	next    uint32
 	mu      *sync.RWMutex
 }
 
+// lastID is shared by all instance systems, so objects of different Apps and
+// detached objects never have the same ID.
+var lastID uint32
+
+// instanceOwned is implemented by objects which record the instance system
+// that assigned their ID.
+type instanceOwned interface {
+	owner() *Instance
+	setOwner(*Instance)
+}
+
 // Setup sets up the System.
 func (s *Instance) Setup() error {
 	return nil
@@ -90,6 +101,9 @@ func (s *Instance) Assign(object Object) error {
 
 	s.objects[id] = object
 	object.SetID(id)
+	if o, ok := object.(instanceOwned); ok {
+		o.setOwner(s)
+	}
 
 	logrus.Debugf("Assigned ID %08X to %s", id, object.Name())
 
@@ -153,22 +167,16 @@ func (s *Instance) ReleaseAll() {
 }
 
 func (s *Instance) nextID() (uint32, error) {
-
 	if len(s.objects) >= math.MaxUint32 {
 		return 0, ErrMaxIDsExceeded
 	}
 
-	id := s.next + 1
-	_, ok := s.objects[id]
-
-	for ok {
-		id := s.next + 1
-		_, ok = s.objects[id]
+	for {
+		id := atomic.AddUint32(&lastID, 1)
+		if _, ok := s.objects[id]; id != 0 && !ok {
+			return id, nil
+		}
 	}
-
-	s.next = id
-
-	return s.next, nil
 }
 
 func (s *Instance) Get(id uint32) (Object, error) {
@@ -193,7 +201,47 @@ func NewInstance() *Instance {
 	return s
 }
 
-// GetInstance gets the instance system from the current app.
+// detachedInstance tracks objects created while no App is current, so objects
+// such as transforms can be created without a running engine.
+var detachedInstance = NewInstance()
+
+// GetInstance gets the instance system from the current app. If no App is
+// current, a detached instance system is returned.
 func GetInstance() *Instance {
-	return CurrentApp().MustSystem(SysNameInstance).(*Instance)
+	return appInstance(CurrentApp())
+}
+
+// Instance returns the instance system of the App.
+func (a *App) Instance() *Instance {
+	return a.MustSystem(SysNameInstance).(*Instance)
+}
+
+// instanceOf returns the instance system which assigned the ID of the object.
+// Objects which do not record it are assumed to belong to the current App.
+func instanceOf(object Object) *Instance {
+	if o, ok := object.(instanceOwned); ok && o.owner() != nil {
+		return o.owner()
+	}
+
+	return GetInstance()
+}
+
+// releaseObjects releases the objects through the instance systems which
+// assigned their IDs.
+func releaseObjects(objects ...Object) {
+	for _, object := range objects {
+		if object != nil && object.ID() != 0 {
+			instanceOf(object).Release(object.ID())
+		}
+	}
+}
+
+// appInstance returns the instance system of the App, or the detached instance
+// system if the App is nil.
+func appInstance(a *App) *Instance {
+	if a == nil {
+		return detachedInstance
+	}
+
+	return a.Instance()
 }
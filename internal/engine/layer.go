@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+// LayerMask is a bitmask of layers. A GameObject belongs to the layers set in
+// its mask, and a Camera only renders objects sharing a layer with its
+// culling mask.
+type LayerMask uint32
+
+const (
+	LayerDefault LayerMask = 1 << iota
+	LayerUI
+	LayerEditor
+)
+
+const (
+	LayerMaskNone LayerMask = 0
+	LayerMaskAll  LayerMask = ^LayerMask(0)
+)
+
+// Contains reports if any of the layers in other are set in the mask.
+func (l LayerMask) Contains(other LayerMask) bool {
+	return l&other != 0
+}
+
+// Layer returns the layer mask of this game object.
+func (g *GameObject) Layer() LayerMask {
+	return g.layer
+}
+
+// SetLayer sets the layer mask of this game object. Camera render caches are
+// rebuilt on the next scene graph update.
+func (g *GameObject) SetLayer(layer LayerMask) {
+	if g.layer == layer {
+		return
+	}
+
+	g.layer = layer
+
+	if g.scene != nil && g.scene.Graph() != nil {
+		g.scene.Graph().SetDirty()
+	}
+}
+
+// CullingMask returns the layers rendered by this camera.
+func (c *Camera) CullingMask() LayerMask {
+	return c.cullingMask
+}
+
+// SetCullingMask sets the layers rendered by this camera.
+func (c *Camera) SetCullingMask(mask LayerMask) {
+	if c.cullingMask == mask {
+		return
+	}
+
+	c.cullingMask = mask
+
+	if g := c.GameObject(); g != nil && g.Scene() != nil && g.Scene().Graph() != nil {
+		g.Scene().Graph().SetDirty()
+	}
+}
+
+// culls reports if the renderer is excluded by the camera culling mask.
+func (c *Camera) culls(r Renderer) bool {
+	if rc, ok := r.(Component); ok && rc.GameObject() != nil {
+		return !c.cullingMask.Contains(rc.GameObject().Layer())
+	}
+
+	return false
+}
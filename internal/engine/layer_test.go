@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+type testRenderer struct {
+	BaseComponent
+
+	deferred bool
+}
+
+func (r *testRenderer) Render(*Camera) {}
+
+func (r *testRenderer) RenderShader(*Shader, *Camera) {}
+
+func (r *testRenderer) SupportsDeferred() bool {
+	return r.deferred
+}
+
+func newTestRenderer(deferred bool) *testRenderer {
+	r := &testRenderer{deferred: deferred}
+
+	r.SetName("TestRenderer")
+	GetInstance().MustAssign(r)
+
+	return r
+}
+
+// addTestRenderer adds an object with a renderer on the given layer.
+func addTestRenderer(t *testing.T, s *SceneGraph, layer LayerMask, deferred bool) (*GameObject, *testRenderer) {
+	g := NewGameObject("renderer")
+	g.SetLayer(layer)
+	r := newTestRenderer(deferred)
+	g.AddComponent(r)
+
+	mustAdd(t, s, g, nil)
+
+	return g, r
+}
+
+func containsRenderer(renderers []Renderer, r Renderer) bool {
+	for i := range renderers {
+		if renderers[i] == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCamera_CullingMask(t *testing.T) {
+	s := newTestScene(t)
+
+	forward := NewCamera(RenderPathForward, false)
+	forward.SetCullingMask(LayerDefault)
+	deferred := NewCamera(RenderPathDeferred, false)
+	deferred.SetCullingMask(LayerUI)
+
+	for _, c := range []*Camera{forward, deferred} {
+		g := NewGameObject("camera")
+		g.AddComponent(c)
+		mustAdd(t, s, g, nil)
+	}
+
+	_, world := addTestRenderer(t, s, LayerDefault, true)
+	_, hud := addTestRenderer(t, s, LayerUI, true)
+	text, overlay := addTestRenderer(t, s, LayerUI, false)
+
+	if len(forward.forwardCache) != 1 || !containsRenderer(forward.forwardCache, world) || len(forward.deferredCache) != 0 {
+		t.Errorf("forward camera caches: %v %v, expected only the default layer", forward.forwardCache, forward.deferredCache)
+	}
+	if len(deferred.deferredCache) != 1 || !containsRenderer(deferred.deferredCache, hud) {
+		t.Errorf("deferred camera deferred cache %v, expected the deferred UI renderer", deferred.deferredCache)
+	}
+	if len(deferred.forwardCache) != 1 || !containsRenderer(deferred.forwardCache, overlay) {
+		t.Errorf("deferred camera forward cache %v, expected the forward UI renderer", deferred.forwardCache)
+	}
+
+	// Layer and mask changes rebuild the caches on the next graph update.
+	text.SetLayer(LayerDefault)
+	if !s.Dirty() {
+		t.Error("graph not marked dirty by SetLayer")
+	}
+	s.Update()
+
+	if !containsRenderer(forward.forwardCache, overlay) || containsRenderer(deferred.forwardCache, overlay) {
+		t.Error("caches not rebuilt after SetLayer")
+	}
+
+	forward.SetCullingMask(LayerMaskNone)
+	if !s.Dirty() {
+		t.Error("graph not marked dirty by SetCullingMask")
+	}
+	s.Update()
+
+	if len(forward.forwardCache) != 0 || len(forward.deferredCache) != 0 {
+		t.Errorf("forward camera caches: %v %v, expected none", forward.forwardCache, forward.deferredCache)
+	}
+}
+
+type testEffect struct {
+	name string
+}
+
+func (e *testEffect) Render(EffectWriter) {}
+
+func (e *testEffect) Type() EffectType {
+	return EffectTypeLDR
+}
+
+func TestCamera_Properties(t *testing.T) {
+	setupTestApp(t)
+
+	c := NewCamera(RenderPathForward, false)
+	c.SetOrthographic(true)
+	c.SetCullingMask(LayerUI)
+	c.AddEffect(&testEffect{name: "first"})
+
+	data, err := c.EncodeProperties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DecodeComponent("Camera", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.(*Camera).Orthographic() {
+		t.Error("orthographic flag lost in a round trip")
+	}
+
+	n, err := c.CloneComponent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := n.(*Camera)
+	if !clone.Orthographic() || clone.CullingMask() != LayerUI || len(clone.effects) != 1 {
+		t.Fatalf("clone orthographic %v mask %v effects %v", clone.Orthographic(), clone.CullingMask(), clone.effects)
+	}
+
+	c.effects[0] = &testEffect{name: "second"}
+	if clone.effects[0] == c.effects[0] {
+		t.Error("clone shares the effects slice of the camera")
+	}
+}
@@ -22,7 +22,11 @@ SOFTWARE.
 
 package engine
 
-import "github.com/go-gl/gl/v4.3-core/gl"
+import (
+	"sort"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
 
 type MaterialTexture uint32
 
@@ -102,6 +106,39 @@ func (m *Material) SetProperty(property string, value interface{}) {
 	m.shaderProperties[property] = value
 }
 
+// Property returns the value of the shader property with the given name.
+func (m *Material) Property(property string) (interface{}, bool) {
+	value, ok := m.shaderProperties[property]
+	return value, ok
+}
+
+// Properties returns the names of all shader properties in sorted order.
+func (m *Material) Properties() []string {
+	names := make([]string, 0, len(m.shaderProperties))
+	for name := range m.shaderProperties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Clone returns a copy of the material with its own shader properties.
+// Shaders and textures are shared with the original.
+func (m *Material) Clone() *Material {
+	n := NewMaterial()
+	n.SetName(m.Name())
+	n.textures = m.textures
+	n.shader = m.shader
+
+	for name, value := range m.shaderProperties {
+		n.shaderProperties[name] = value
+	}
+
+	return n
+}
+
 func NewMaterial() *Material {
 	m := &Material{
 		shaderProperties: make(map[string]interface{}),
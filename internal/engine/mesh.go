@@ -37,10 +37,17 @@ type Mesh struct {
 	normals        []mgl32.Vec3
 	uvs            []mgl32.Vec2
 	triangles      []uint32
+	joints         [][4]uint32
+	weights        []mgl32.Vec4
+	jointNames     []string
+	bindPoses      []mgl32.Mat4
+	morphTargets   []*MorphTarget
 	vao            uint32
 	vbo            uint32
 	ibo            uint32
+	svbo           uint32
 	reverseWinding bool
+	dynamic        bool
 }
 
 type MeshPoint struct {
@@ -49,6 +56,16 @@ type MeshPoint struct {
 	U mgl32.Vec2
 }
 
+// SkinPoint holds the joints influencing a vertex and their weights.
+type SkinPoint struct {
+	J [4]uint32
+	W mgl32.Vec4
+}
+
+// MaxJoints is the maximum number of joints of a skinned mesh. It must match
+// the size of the joint matrix array in the standard shader.
+const MaxJoints = 64
+
 // NewMesh creates a new mesh object.
 func NewMesh() *Mesh {
 	m := &Mesh{}
@@ -79,11 +96,24 @@ func (m *Mesh) Alloc() error {
 	return m.Upload()
 }
 
-// Dealloc releases builtin for this mesh.
+// Dealloc releases builtin for this mesh. Meshes which were never allocated,
+// like meshes created by headless Apps, own no GL objects and are skipped.
 func (m *Mesh) Dealloc() {
+	if m.vao == 0 && m.vbo == 0 {
+		return
+	}
+	if a := CurrentApp(); a != nil && a.Headless() {
+		return
+	}
+
 	gl.DeleteBuffers(1, &m.vbo)
 	gl.DeleteBuffers(1, &m.ibo)
+	if m.svbo != 0 {
+		gl.DeleteBuffers(1, &m.svbo)
+	}
 	gl.DeleteVertexArrays(1, &m.vao)
+
+	m.vao, m.vbo, m.ibo, m.svbo = 0, 0, 0, 0
 }
 
 func (m *Mesh) Bind() {
@@ -107,6 +137,9 @@ func (m *Mesh) Clear() {
 	m.normals = m.normals[:0]
 	m.uvs = m.uvs[:0]
 	m.triangles = m.triangles[:0]
+	m.joints = m.joints[:0]
+	m.weights = m.weights[:0]
+	m.morphTargets = nil
 }
 
 func (m *Mesh) Upload() error {
@@ -123,9 +156,85 @@ func (m *Mesh) Upload() error {
 		data[idx] = MeshPoint{m.vertices[idx], m.normals[idx], m.uvs[idx]}
 	}
 
+	if err := m.validateSkin(); err != nil {
+		return err
+	}
+
 	m.Bind()
 
+	usage := uint32(gl.STATIC_DRAW)
+	if m.dynamic {
+		usage = gl.DYNAMIC_DRAW
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(data)*32, gl.Ptr(data), usage)
+
+	if m.Skinned() {
+		m.uploadSkin()
+	}
+
+	return nil
+}
+
+// uploadVertices updates the vertex buffer of an allocated mesh in place after
+// its vertices or normals changed. The skin buffer is left as is.
+func (m *Mesh) uploadVertices() {
+	data := make([]MeshPoint, len(m.vertices))
+	for idx := range m.vertices {
+		data[idx] = MeshPoint{m.vertices[idx], m.normals[idx], m.uvs[idx]}
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.vbo)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(data)*32, gl.Ptr(data))
+}
+
+// uploadSkin uploads the joint indices and weights into a second vertex
+// buffer, which is only allocated for skinned meshes.
+func (m *Mesh) uploadSkin() {
+	if m.svbo == 0 {
+		gl.GenBuffers(1, &m.svbo)
+		gl.BindBuffer(gl.ARRAY_BUFFER, m.svbo)
+
+		gl.EnableVertexAttribArray(3)
+		gl.VertexAttribIPointer(3, 4, gl.UNSIGNED_INT, 32, gl.PtrOffset(0))
+		gl.EnableVertexAttribArray(4)
+		gl.VertexAttribPointer(4, 4, gl.FLOAT, false, 32, gl.PtrOffset(16))
+	}
+
+	data := make([]SkinPoint, len(m.joints))
+	for idx := range m.joints {
+		data[idx] = SkinPoint{m.joints[idx], m.weights[idx]}
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.svbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(data)*32, gl.Ptr(data), gl.STATIC_DRAW)
+}
+
+// validateSkin checks that the skin data matches the geometry and the joints
+// of the mesh.
+func (m *Mesh) validateSkin() error {
+	if len(m.joints) == 0 && len(m.weights) == 0 {
+		return nil
+	}
+
+	if len(m.joints) != len(m.vertices) || len(m.weights) != len(m.vertices) {
+		return fmt.Errorf("mesh upload failed: vao %d has invalid skin definition: asymmetric data", m.vao)
+	}
+	if len(m.jointNames) == 0 || len(m.jointNames) > MaxJoints {
+		return fmt.Errorf("mesh upload failed: vao %d has invalid skin definition: %d joints", m.vao, len(m.jointNames))
+	}
+	if len(m.bindPoses) != 0 && len(m.bindPoses) != len(m.jointNames) {
+		return fmt.Errorf("mesh upload failed: vao %d has invalid skin definition: %d bind poses for %d joints", m.vao, len(m.bindPoses), len(m.jointNames))
+	}
+
+	for idx := range m.joints {
+		for j := range m.joints[idx] {
+			if m.weights[idx][j] != 0 && int(m.joints[idx][j]) >= len(m.jointNames) {
+				return fmt.Errorf("mesh upload failed: vao %d has invalid skin definition: vertex %d references joint %d", m.vao, idx, m.joints[idx][j])
+			}
+		}
+	}
 
 	return nil
 }
@@ -146,6 +255,32 @@ func (m *Mesh) Triangles() []uint32 {
 	return m.triangles
 }
 
+// Joints returns the indices of the joints influencing each vertex.
+func (m *Mesh) Joints() [][4]uint32 {
+	return m.joints
+}
+
+// Weights returns the joint weights of each vertex.
+func (m *Mesh) Weights() []mgl32.Vec4 {
+	return m.weights
+}
+
+// JointNames returns the paths of the joints of the skin, relative to the
+// root of the skeleton.
+func (m *Mesh) JointNames() []string {
+	return m.jointNames
+}
+
+// BindPoses returns the inverse bind matrices of the joints.
+func (m *Mesh) BindPoses() []mgl32.Mat4 {
+	return m.bindPoses
+}
+
+// Skinned reports if the mesh has joint weights.
+func (m *Mesh) Skinned() bool {
+	return len(m.joints) != 0
+}
+
 func (m *Mesh) Indexed() bool {
 	return len(m.triangles) != 0
 }
@@ -166,6 +301,19 @@ func (m *Mesh) SetUvs(uvs []mgl32.Vec2) {
 	m.uvs = uvs
 }
 
+// SetSkin sets the joints influencing each vertex and their weights.
+func (m *Mesh) SetSkin(joints [][4]uint32, weights []mgl32.Vec4) {
+	m.joints = joints
+	m.weights = weights
+}
+
+// SetJoints sets the paths of the joints of the skin and their inverse bind
+// matrices. Without bind poses the joints are bound at the origin.
+func (m *Mesh) SetJoints(names []string, bindPoses []mgl32.Mat4) {
+	m.jointNames = names
+	m.bindPoses = bindPoses
+}
+
 func (m *Mesh) SetReversedWinding(reverse bool) {
 	m.reverseWinding = reverse
 }
@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MorphTarget is a blend shape of a mesh. It holds the position and normal
+// offsets of every vertex of the mesh at full weight.
+type MorphTarget struct {
+	Name      string
+	Positions []mgl32.Vec3
+	Normals   []mgl32.Vec3
+}
+
+// MorphTargets returns the morph targets of the mesh.
+func (m *Mesh) MorphTargets() []*MorphTarget {
+	return m.morphTargets
+}
+
+// AddMorphTarget adds a morph target to the mesh. The normal offsets may be
+// nil.
+func (m *Mesh) AddMorphTarget(target *MorphTarget) error {
+	if len(target.Positions) != len(m.vertices) {
+		return fmt.Errorf("morph target %s has %d positions, mesh has %d vertices", target.Name, len(target.Positions), len(m.vertices))
+	}
+	if len(target.Normals) != 0 && len(target.Normals) != len(m.vertices) {
+		return fmt.Errorf("morph target %s has %d normals, mesh has %d vertices", target.Name, len(target.Normals), len(m.vertices))
+	}
+	if m.MorphTargetIndex(target.Name) >= 0 {
+		return fmt.Errorf("morph target %s already exists", target.Name)
+	}
+
+	m.morphTargets = append(m.morphTargets, target)
+
+	return nil
+}
+
+// MorphTargetIndex returns the index of the morph target with the given name,
+// or -1.
+func (m *Mesh) MorphTargetIndex(name string) int {
+	for i := range m.morphTargets {
+		if m.morphTargets[i].Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// BlendMorphTargets writes the vertices and normals of the mesh with the
+// morph targets applied at the given weights. Blended normals are normalized.
+func (m *Mesh) BlendMorphTargets(weights []float32, vertices, normals []mgl32.Vec3) {
+	copy(vertices, m.vertices)
+	copy(normals, m.normals)
+
+	normalsChanged := false
+	for i, w := range weights {
+		if w == 0 || i >= len(m.morphTargets) {
+			continue
+		}
+
+		target := m.morphTargets[i]
+		for j := range target.Positions {
+			vertices[j] = addScaled(vertices[j], target.Positions[j], w)
+		}
+		for j := range target.Normals {
+			normals[j] = addScaled(normals[j], target.Normals[j], w)
+			normalsChanged = true
+		}
+	}
+
+	if normalsChanged {
+		for j := range normals {
+			normals[j] = normals[j].Normalize()
+		}
+	}
+}
+
+func addScaled(v, d mgl32.Vec3, w float32) mgl32.Vec3 {
+	return mgl32.Vec3{v[0] + d[0]*w, v[1] + d[1]*w, v[2] + d[2]*w}
+}
+
+// MeshMorpher blends the morph targets of a mesh on the CPU into a dynamic
+// copy of the mesh. The base mesh is usually a shared asset, so every
+// renderer needs its own MeshMorpher.
+type MeshMorpher struct {
+	base    *Mesh
+	mesh    *Mesh
+	weights []float32
+	dirty   bool
+}
+
+// NewMeshMorpher creates a new MeshMorpher for the base mesh.
+func NewMeshMorpher(base *Mesh) *MeshMorpher {
+	return &MeshMorpher{
+		base:    base,
+		weights: make([]float32, len(base.MorphTargets())),
+	}
+}
+
+// Base returns the mesh being morphed.
+func (m *MeshMorpher) Base() *Mesh {
+	return m.base
+}
+
+// Weight returns the weight of the morph target with the given index. Morph
+// targets without a weight, including targets added to the base mesh after
+// the morpher was created, have a weight of zero.
+func (m *MeshMorpher) Weight(i int) float32 {
+	if i < 0 || i >= len(m.weights) {
+		return 0
+	}
+
+	return m.weights[i]
+}
+
+// SetWeight sets the weight of the morph target with the given index. Indices
+// outside the morph targets of the base mesh are ignored.
+func (m *MeshMorpher) SetWeight(i int, weight float32) {
+	if i < 0 || i >= len(m.base.MorphTargets()) {
+		return
+	}
+	if i >= len(m.weights) {
+		m.weights = append(m.weights, make([]float32, len(m.base.MorphTargets())-len(m.weights))...)
+	}
+
+	if m.weights[i] != weight {
+		m.weights[i] = weight
+		m.dirty = true
+	}
+}
+
+// Active reports if any morph target has a non-zero weight.
+func (m *MeshMorpher) Active() bool {
+	for _, w := range m.weights {
+		if w != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mesh returns the mesh to draw. If no morph target is active this is the
+// base mesh. Otherwise the blended copy is returned, uploaded again if the
+// weights changed since the last call.
+func (m *MeshMorpher) Mesh() (*Mesh, error) {
+	if !m.Active() {
+		return m.base, nil
+	}
+
+	if m.mesh == nil {
+		m.mesh = NewMesh()
+		m.mesh.SetName(m.base.Name() + " (morphed)")
+		m.mesh.vertices = make([]mgl32.Vec3, len(m.base.vertices))
+		m.mesh.normals = make([]mgl32.Vec3, len(m.base.normals))
+		m.mesh.uvs = m.base.uvs
+		m.mesh.triangles = m.base.triangles
+		m.mesh.joints = m.base.joints
+		m.mesh.weights = m.base.weights
+		m.mesh.jointNames = m.base.jointNames
+		m.mesh.bindPoses = m.base.bindPoses
+		m.mesh.reverseWinding = m.base.reverseWinding
+		m.mesh.dynamic = true
+
+		m.base.BlendMorphTargets(m.weights, m.mesh.vertices, m.mesh.normals)
+		if err := m.mesh.Alloc(); err != nil {
+			return nil, err
+		}
+		m.dirty = false
+	}
+
+	if m.dirty {
+		m.base.BlendMorphTargets(m.weights, m.mesh.vertices, m.mesh.normals)
+		m.mesh.uploadVertices()
+		m.dirty = false
+	}
+
+	return m.mesh, nil
+}
+
+// Release frees the blended copy of the mesh through the instance system
+// which assigned its ID.
+func (m *MeshMorpher) Release() {
+	if m.mesh != nil {
+		instanceOf(m.mesh).Release(m.mesh.ID())
+		m.mesh = nil
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func newMorphMesh(t *testing.T) *Mesh {
+	m := NewMesh()
+	m.SetVertices([]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}})
+	m.SetNormals([]mgl32.Vec3{{0, 0, 1}, {0, 0, 1}})
+
+	for _, target := range []*MorphTarget{
+		{Name: "up", Positions: []mgl32.Vec3{{0, 1, 0}, {0, 1, 0}}},
+		{Name: "stretch", Positions: []mgl32.Vec3{{0, 0, 0}, {2, 0, 0}}},
+	} {
+		if err := m.AddMorphTarget(target); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return m
+}
+
+func TestMesh_AddMorphTarget(t *testing.T) {
+	setupTestApp(t)
+
+	m := newMorphMesh(t)
+
+	if m.MorphTargetIndex("stretch") != 1 || m.MorphTargetIndex("missing") != -1 {
+		t.Errorf("unexpected indices %d %d", m.MorphTargetIndex("stretch"), m.MorphTargetIndex("missing"))
+	}
+	if err := m.AddMorphTarget(&MorphTarget{Name: "up", Positions: make([]mgl32.Vec3, 2)}); err == nil {
+		t.Error("expected error for duplicate morph target")
+	}
+	if err := m.AddMorphTarget(&MorphTarget{Name: "short", Positions: make([]mgl32.Vec3, 1)}); err == nil {
+		t.Error("expected error for morph target with too few positions")
+	}
+	if err := m.AddMorphTarget(&MorphTarget{Name: "normals", Positions: make([]mgl32.Vec3, 2), Normals: make([]mgl32.Vec3, 3)}); err == nil {
+		t.Error("expected error for morph target with too many normals")
+	}
+}
+
+func TestMesh_BlendMorphTargets(t *testing.T) {
+	setupTestApp(t)
+
+	m := newMorphMesh(t)
+	vertices := make([]mgl32.Vec3, 2)
+	normals := make([]mgl32.Vec3, 2)
+
+	m.BlendMorphTargets([]float32{0.5, 1}, vertices, normals)
+
+	expected := []mgl32.Vec3{{0, 0.5, 0}, {3, 0.5, 0}}
+	for i := range expected {
+		if vertices[i] != expected[i] {
+			t.Errorf("vertex %d: %v, expected %v", i, vertices[i], expected[i])
+		}
+	}
+	if normals[0] != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("normal changed without normal offsets: %v", normals[0])
+	}
+	if m.Vertices()[1] != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("base mesh modified: %v", m.Vertices()[1])
+	}
+}
+
+func TestMeshMorpher_Inactive(t *testing.T) {
+	setupTestApp(t)
+
+	m := newMorphMesh(t)
+	morpher := NewMeshMorpher(m)
+
+	if morpher.Active() {
+		t.Fatal("new morpher is active")
+	}
+	if mesh, err := morpher.Mesh(); err != nil || mesh != m {
+		t.Errorf("inactive morpher returned %v, %v", mesh, err)
+	}
+
+	morpher.SetWeight(1, 0.25)
+	if !morpher.Active() || morpher.Weight(1) != 0.25 {
+		t.Errorf("weight %v, active %v", morpher.Weight(1), morpher.Active())
+	}
+}
+
+func TestMeshMorpher_LateMorphTarget(t *testing.T) {
+	setupTestApp(t)
+
+	m := newMorphMesh(t)
+	morpher := NewMeshMorpher(m)
+
+	if err := m.AddMorphTarget(&MorphTarget{Name: "late", Positions: make([]mgl32.Vec3, 2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := morpher.Weight(2); w != 0 {
+		t.Errorf("weight %v of late morph target, expected 0", w)
+	}
+	morpher.SetWeight(2, 0.5)
+	if w := morpher.Weight(2); w != 0.5 {
+		t.Errorf("weight %v of late morph target, expected 0.5", w)
+	}
+
+	morpher.SetWeight(3, 1)
+	morpher.SetWeight(-1, 1)
+	if w := morpher.Weight(3); w != 0 {
+		t.Errorf("weight %v of missing morph target, expected 0", w)
+	}
+}
+
+func TestMeshMorpher_Release(t *testing.T) {
+	a := setupTestApp(t)
+
+	morpher := NewMeshMorpher(newMorphMesh(t))
+	morpher.mesh = NewMesh()
+	id := morpher.mesh.ID()
+
+	// The copy is released through the App which assigned its ID, even if
+	// another App is current.
+	setupTestApp(t)
+	morpher.Release()
+
+	if _, err := a.Instance().Get(id); err == nil {
+		t.Error("morphed mesh not released")
+	}
+	if morpher.mesh != nil {
+		t.Error("morphed mesh kept after release")
+	}
+}
+
+func TestFindAnimationProperty_Resolver(t *testing.T) {
+	RegisterAnimationPropertyResolver("animTarget", func(property string) (*AnimationProperty, bool) {
+		if property != "resolved" {
+			return nil, false
+		}
+
+		return &AnimationProperty{Size: 2}, true
+	})
+	t.Cleanup(func() {
+		animationPropertiesMu.Lock()
+		delete(animationResolvers, "animTarget")
+		animationPropertiesMu.Unlock()
+	})
+
+	if p, ok := FindAnimationProperty("animTarget", "value"); !ok || p.Size != 1 {
+		t.Error("registered property not preferred over resolver")
+	}
+	if p, ok := FindAnimationProperty("animTarget", "resolved"); !ok || p.Size != 2 {
+		t.Error("resolver not used")
+	}
+	if _, ok := FindAnimationProperty("animTarget", "missing"); ok {
+		t.Error("unexpected property")
+	}
+}
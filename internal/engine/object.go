@@ -57,8 +57,9 @@ type Object interface {
 // Object is a compliant implementation of the Object interface. All types that
 // intend to implement that interface should embed this struct.
 type BaseObject struct {
-	id   uint32
-	name string
+	id       uint32
+	name     string
+	instance *Instance
 }
 
 // ID returns the instance ID of this object.
@@ -103,4 +104,13 @@ func (o *BaseObject) String() string {
 // Release will set the instance ID of this object to 0.
 func (o *BaseObject) Release() {
 	o.id = 0
+	o.instance = nil
+}
+
+func (o *BaseObject) owner() *Instance {
+	return o.instance
+}
+
+func (o *BaseObject) setOwner(instance *Instance) {
+	o.instance = instance
 }
@@ -307,7 +307,7 @@ func (p *ParticleRenderer) Render(camera *Camera) {
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
 
-	p.renderShader.SetUniform("v_model_matrix", p.GetTransform().ActiveMatrix())
+	p.renderShader.SetUniform("v_model_matrix", p.GetTransform().RenderMatrix())
 	p.renderShader.SetUniform("v_view_matrix", camera.ViewMatrix())
 	p.renderShader.SetUniform("v_projection_matrix", camera.ProjectionMatrix())
 
@@ -0,0 +1,564 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+var _ System = &Profiler{}
+
+const SysNameProfiler = "profiler"
+
+// DefaultProfileWindow is the number of frames rolling averages are taken
+// over.
+const DefaultProfileWindow = 60
+
+const (
+	ProfileCategoryCPU = "cpu"
+	ProfileCategoryGPU = "gpu"
+)
+
+// ProfileEvent is a completed profiler scope.
+type ProfileEvent struct {
+	Name     string
+	Category string
+	Frame    uint64
+	Depth    int
+
+	// Start is the time since the profiler was created. GPU events start at
+	// the CPU time their commands were issued.
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// ProfileStat holds the rolling statistics of a scope over the last frames
+// the scope ran in. Durations of a scope entered several times in a frame are
+// summed.
+type ProfileStat struct {
+	Name     string
+	Category string
+	Average  time.Duration
+	Max      time.Duration
+	Samples  int
+}
+
+// ProfileCapture is a sequence of profiler events recorded over several frames.
+type ProfileCapture struct {
+	Events []ProfileEvent
+	Frames int
+}
+
+// ProfileScope is an open profiler scope. End must be called on the same
+// goroutine, in the reverse order scopes were begun.
+type ProfileScope struct {
+	profiler *Profiler
+	query    *gpuQuery
+	name     string
+	start    time.Time
+	depth    int
+}
+
+type profileKey struct {
+	name     string
+	category string
+}
+
+type profileWindow struct {
+	samples []time.Duration
+	next    int
+	count   int
+}
+
+type gpuQuery struct {
+	ids   [2]uint32
+	name  string
+	frame uint64
+	depth int
+	start time.Duration
+}
+
+// Profiler implements a frame profiler. CPU scopes are timed with the wall
+// clock, GPU scopes additionally with OpenGL timestamp queries, which are read
+// back without stalling once the GPU has finished them.
+type Profiler struct {
+	epoch       time.Time
+	frameTotals map[profileKey]time.Duration
+	windows     map[profileKey]*profileWindow
+	pending     []*gpuQuery
+	queryPool   []uint32
+	capture     *ProfileCapture
+	lastCapture *ProfileCapture
+	mu          sync.Mutex
+	frame       uint64
+	window      int
+	depth       int
+	frames      int
+	enabled     bool
+	gpu         bool
+	gpuCapable  bool
+}
+
+// Setup sets up the System.
+func (p *Profiler) Setup() error {
+	return nil
+}
+
+// Teardown tears down the System.
+func (p *Profiler) Teardown() {
+	for i := range p.pending {
+		p.queryPool = append(p.queryPool, p.pending[i].ids[:]...)
+	}
+	p.pending = nil
+
+	if len(p.queryPool) != 0 {
+		gl.DeleteQueries(int32(len(p.queryPool)), &p.queryPool[0])
+		p.queryPool = nil
+	}
+}
+
+// Name returns the name of the System.
+func (p *Profiler) Name() string {
+	return SysNameProfiler
+}
+
+// Dependencies returns the names of the systems the System depends on. GPU
+// queries must be deleted while the OpenGL context exists.
+func (p *Profiler) Dependencies() []string {
+	return []string{SysNameWindow}
+}
+
+// Enabled reports if the profiler records scopes.
+func (p *Profiler) Enabled() bool {
+	return p != nil && p.enabled
+}
+
+// SetEnabled enables or disables the profiler.
+func (p *Profiler) SetEnabled(enabled bool) {
+	p.enabled = enabled
+}
+
+// GPUTiming reports if GPU scopes are timed with OpenGL queries.
+func (p *Profiler) GPUTiming() bool {
+	return p.gpu
+}
+
+// SetGPUTiming enables or disables OpenGL timer queries. They cannot be enabled
+// without an OpenGL context.
+func (p *Profiler) SetGPUTiming(gpu bool) {
+	p.gpu = gpu && p.gpuCapable
+}
+
+// Frame returns the number of the current frame.
+func (p *Profiler) Frame() uint64 {
+	return p.frame
+}
+
+// Begin opens a CPU scope with the given name. A nil or disabled profiler
+// returns a scope which does nothing when ended.
+func (p *Profiler) Begin(name string) ProfileScope {
+	if !p.Enabled() {
+		return ProfileScope{}
+	}
+
+	s := ProfileScope{
+		profiler: p,
+		name:     name,
+		start:    time.Now(),
+		depth:    p.depth,
+	}
+	p.depth++
+
+	return s
+}
+
+// BeginGPU opens a scope around GPU commands. The scope is recorded as a CPU
+// scope, and as a GPU scope if GPU timing is enabled.
+func (p *Profiler) BeginGPU(name string) ProfileScope {
+	s := p.Begin(name)
+	if s.profiler == nil || !p.gpu {
+		return s
+	}
+
+	s.query = &gpuQuery{
+		ids:   p.queries(),
+		name:  name,
+		frame: p.frame,
+		depth: s.depth,
+		start: s.start.Sub(p.epoch),
+	}
+	gl.QueryCounter(s.query.ids[0], gl.TIMESTAMP)
+
+	return s
+}
+
+// End closes the scope.
+func (s ProfileScope) End() {
+	p := s.profiler
+	if p == nil {
+		return
+	}
+
+	duration := time.Since(s.start)
+	p.depth--
+
+	if s.query != nil {
+		gl.QueryCounter(s.query.ids[1], gl.TIMESTAMP)
+		p.pending = append(p.pending, s.query)
+	}
+
+	p.record(ProfileEvent{
+		Name:     s.name,
+		Category: ProfileCategoryCPU,
+		Frame:    p.frame,
+		Depth:    s.depth,
+		Start:    s.start.Sub(p.epoch),
+		Duration: duration,
+	})
+}
+
+// BeginFrame starts a new frame.
+func (p *Profiler) BeginFrame() {
+	p.depth = 0
+}
+
+// EndFrame reads back finished GPU queries, updates the rolling statistics
+// and advances the frame counter.
+func (p *Profiler) EndFrame() {
+	p.resolveQueries()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, d := range p.frameTotals {
+		w, ok := p.windows[k]
+		if !ok {
+			w = &profileWindow{samples: make([]time.Duration, p.window)}
+			p.windows[k] = w
+		}
+		w.add(d)
+		delete(p.frameTotals, k)
+	}
+
+	if p.capture != nil {
+		p.capture.Frames++
+		if p.frames > 0 && p.capture.Frames >= p.frames {
+			p.lastCapture = p.capture
+			p.capture = nil
+		}
+	}
+
+	p.frame++
+}
+
+// StartCapture starts recording events. The capture ends after the given
+// number of frames, or when StopCapture is called if frames is zero.
+func (p *Profiler) StartCapture(frames int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.capture = &ProfileCapture{}
+	p.frames = frames
+}
+
+// StopCapture ends the current capture and returns it. It returns nil if no
+// capture is running.
+func (p *Profiler) StopCapture() *ProfileCapture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c := p.capture
+	if c != nil {
+		p.lastCapture = c
+		p.capture = nil
+	}
+
+	return c
+}
+
+// Capturing reports if a capture is running.
+func (p *Profiler) Capturing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.capture != nil
+}
+
+// LastCapture returns the last completed capture, or nil if there is none.
+func (p *Profiler) LastCapture() *ProfileCapture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastCapture
+}
+
+// Stat returns the rolling statistics of the CPU scope with the given name.
+func (p *Profiler) Stat(name string) (ProfileStat, bool) {
+	return p.stat(profileKey{name, ProfileCategoryCPU})
+}
+
+// GPUStat returns the rolling statistics of the GPU scope with the given name.
+func (p *Profiler) GPUStat(name string) (ProfileStat, bool) {
+	return p.stat(profileKey{name, ProfileCategoryGPU})
+}
+
+// Average returns the rolling average duration of the CPU scope with the given
+// name, or zero if the scope has not run.
+func (p *Profiler) Average(name string) time.Duration {
+	s, _ := p.Stat(name)
+	return s.Average
+}
+
+// Stats returns the rolling statistics of all scopes, sorted by category and
+// name.
+func (p *Profiler) Stats() []ProfileStat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ProfileStat, 0, len(p.windows))
+	for k, w := range p.windows {
+		stats = append(stats, w.stat(k))
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Category != stats[j].Category {
+			return stats[i].Category < stats[j].Category
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+// ResetStats discards all rolling statistics.
+func (p *Profiler) ResetStats() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.windows = make(map[profileKey]*profileWindow)
+}
+
+func (p *Profiler) stat(k profileKey) (ProfileStat, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	w, ok := p.windows[k]
+	if !ok {
+		return ProfileStat{Name: k.name, Category: k.category}, false
+	}
+
+	return w.stat(k), true
+}
+
+func (p *Profiler) record(e ProfileEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.frameTotals[profileKey{e.Name, e.Category}] += e.Duration
+
+	if p.capture != nil {
+		p.capture.Events = append(p.capture.Events, e)
+	}
+}
+
+// queries returns a pair of query objects, reusing finished ones.
+func (p *Profiler) queries() [2]uint32 {
+	if len(p.queryPool) < 2 {
+		ids := make([]uint32, 16)
+		gl.GenQueries(int32(len(ids)), &ids[0])
+		p.queryPool = append(p.queryPool, ids...)
+	}
+
+	n := len(p.queryPool)
+	ids := [2]uint32{p.queryPool[n-2], p.queryPool[n-1]}
+	p.queryPool = p.queryPool[:n-2]
+
+	return ids
+}
+
+// resolveQueries records the GPU scopes whose queries are available. Queries
+// finish in order, so resolving stops at the first one still pending.
+func (p *Profiler) resolveQueries() {
+	n := 0
+
+	for _, q := range p.pending {
+		var available int32
+		gl.GetQueryObjectiv(q.ids[1], gl.QUERY_RESULT_AVAILABLE, &available)
+		if available == 0 {
+			break
+		}
+
+		var start, end uint64
+		gl.GetQueryObjectui64v(q.ids[0], gl.QUERY_RESULT, &start)
+		gl.GetQueryObjectui64v(q.ids[1], gl.QUERY_RESULT, &end)
+
+		p.record(ProfileEvent{
+			Name:     q.name,
+			Category: ProfileCategoryGPU,
+			Frame:    q.frame,
+			Depth:    q.depth,
+			Start:    q.start,
+			Duration: time.Duration(end - start),
+		})
+
+		p.queryPool = append(p.queryPool, q.ids[:]...)
+		n++
+	}
+
+	p.pending = append(p.pending[:0], p.pending[n:]...)
+}
+
+func (w *profileWindow) add(d time.Duration) {
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+}
+
+func (w *profileWindow) stat(k profileKey) ProfileStat {
+	s := ProfileStat{
+		Name:     k.name,
+		Category: k.category,
+		Samples:  w.count,
+	}
+
+	var sum time.Duration
+	for i := 0; i < w.count; i++ {
+		sum += w.samples[i]
+		if w.samples[i] > s.Max {
+			s.Max = w.samples[i]
+		}
+	}
+	if w.count != 0 {
+		s.Average = sum / time.Duration(w.count)
+	}
+
+	return s
+}
+
+type traceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   float64                `json:"ts"`
+	Dur  float64                `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type traceFile struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteTrace writes the capture in the Chrome trace event format, which can be
+// opened with chrome://tracing or Perfetto. CPU and GPU scopes are shown as
+// separate threads.
+func (c *ProfileCapture) WriteTrace(w io.Writer) error {
+	f := traceFile{
+		TraceEvents: []traceEvent{
+			{Name: "thread_name", Ph: "M", Pid: 1, Tid: 1, Args: map[string]interface{}{"name": "CPU"}},
+			{Name: "thread_name", Ph: "M", Pid: 1, Tid: 2, Args: map[string]interface{}{"name": "GPU"}},
+		},
+		DisplayTimeUnit: "ms",
+	}
+
+	for i := range c.Events {
+		e := &c.Events[i]
+
+		tid := 1
+		if e.Category == ProfileCategoryGPU {
+			tid = 2
+		}
+
+		f.TraceEvents = append(f.TraceEvents, traceEvent{
+			Name: e.Name,
+			Cat:  e.Category,
+			Ph:   "X",
+			Ts:   float64(e.Start) / float64(time.Microsecond),
+			Dur:  float64(e.Duration) / float64(time.Microsecond),
+			Pid:  1,
+			Tid:  tid,
+			Args: map[string]interface{}{"frame": e.Frame},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(&f)
+}
+
+// SaveTrace writes the capture to the file with the given name in the Chrome
+// trace event format.
+func (c *ProfileCapture) SaveTrace(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := c.WriteTrace(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// NewProfiler creates a new profiler system. GPU timing is available and
+// enabled if gpu is true, which requires an OpenGL context.
+func NewProfiler(gpu bool) *Profiler {
+	p := &Profiler{
+		epoch:       time.Now(),
+		frameTotals: make(map[profileKey]time.Duration),
+		windows:     make(map[profileKey]*profileWindow),
+		window:      DefaultProfileWindow,
+		enabled:     true,
+		gpu:         gpu,
+		gpuCapable:  gpu,
+	}
+
+	return p
+}
+
+// GetProfiler gets the profiler system from the current app. It returns nil if
+// no App is current, which disables profiling.
+func GetProfiler() *Profiler {
+	return CurrentApp().Profiler()
+}
+
+// Profiler returns the profiler system of the App, or nil if the App is nil or
+// has not been set up.
+func (a *App) Profiler() *Profiler {
+	if a == nil || !a.SystemRegistered(SysNameProfiler) {
+		return nil
+	}
+
+	return a.MustSystem(SysNameProfiler).(*Profiler)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestProfiler_Scopes(t *testing.T) {
+	p := NewProfiler(false)
+
+	for i := 0; i < 3; i++ {
+		p.BeginFrame()
+		outer := p.Begin("outer")
+		inner := p.Begin("inner")
+		time.Sleep(time.Millisecond)
+		inner.End()
+		outer.End()
+		p.EndFrame()
+	}
+
+	outer, ok := p.Stat("outer")
+	if !ok {
+		t.Fatal("no stats for outer scope")
+	}
+	if outer.Samples != 3 {
+		t.Errorf("samples: %d, expected 3", outer.Samples)
+	}
+	if p.Average("inner") < time.Millisecond || outer.Average < p.Average("inner") {
+		t.Errorf("unexpected averages: outer %v, inner %v", outer.Average, p.Average("inner"))
+	}
+	if len(p.Stats()) != 2 {
+		t.Errorf("stats: %d, expected 2", len(p.Stats()))
+	}
+}
+
+func TestProfiler_Disabled(t *testing.T) {
+	var p *Profiler
+	p.Begin("nil").End()
+
+	p = NewProfiler(false)
+	p.SetEnabled(false)
+	p.Begin("disabled").End()
+	p.EndFrame()
+
+	if _, ok := p.Stat("disabled"); ok {
+		t.Error("disabled profiler recorded a scope")
+	}
+}
+
+func TestProfiler_CaptureTrace(t *testing.T) {
+	p := NewProfiler(false)
+	p.StartCapture(2)
+
+	for i := 0; i < 3; i++ {
+		p.BeginFrame()
+		p.Begin("frame").End()
+		p.EndFrame()
+	}
+
+	if p.Capturing() {
+		t.Fatal("capture did not end after 2 frames")
+	}
+
+	c := p.LastCapture()
+	if c == nil || c.Frames != 2 || len(c.Events) != 2 {
+		t.Fatalf("unexpected capture %+v", c)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.WriteTrace(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	trace := struct {
+		TraceEvents []struct {
+			Name string `json:"name"`
+			Ph   string `json:"ph"`
+		} `json:"traceEvents"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+
+	complete := 0
+	for _, e := range trace.TraceEvents {
+		if e.Ph == "X" && e.Name == "frame" {
+			complete++
+		}
+	}
+	if complete != 2 {
+		t.Errorf("trace has %d frame events, expected 2", complete)
+	}
+}
+
+func TestApp_Profiler(t *testing.T) {
+	a := setupTestApp(t)
+
+	a.maxFrames = 2
+
+	if err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"frame", "update", "render"} {
+		if s, ok := a.Profiler().Stat(name); !ok || s.Samples != 2 {
+			t.Errorf("%s: %+v, expected 2 samples", name, s)
+		}
+	}
+}
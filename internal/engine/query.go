@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"strings"
+)
+
+// GetComponent returns the first component of type T attached to the game
+// object, or the zero value of T if there is none. T may be a concrete
+// component type such as *Camera or an interface such as Renderer.
+func GetComponent[T any](g *GameObject) T {
+	var zero T
+
+	for i := range g.components {
+		if c, ok := g.components[i].(T); ok {
+			return c
+		}
+	}
+
+	return zero
+}
+
+// GetComponents returns all components of type T attached to the game object.
+func GetComponents[T any](g *GameObject) []T {
+	components := []T{}
+
+	for i := range g.components {
+		if c, ok := g.components[i].(T); ok {
+			components = append(components, c)
+		}
+	}
+
+	return components
+}
+
+// GetComponentInChildren returns the first component of type T attached to
+// the game object or any of its descendants, searched depth first.
+func GetComponentInChildren[T any](g *GameObject) T {
+	for _, o := range g.hierarchy() {
+		for i := range o.components {
+			if c, ok := o.components[i].(T); ok {
+				return c
+			}
+		}
+	}
+
+	var zero T
+
+	return zero
+}
+
+// GetComponentsInChildren returns all components of type T attached to the
+// game object or any of its descendants.
+func GetComponentsInChildren[T any](g *GameObject) []T {
+	components := []T{}
+
+	for _, o := range g.hierarchy() {
+		components = append(components, GetComponents[T](o)...)
+	}
+
+	return components
+}
+
+// FindComponents returns all active components of type T in the scene graph.
+// Results are indexed by type and reused until the next SceneGraph.Update.
+func FindComponents[T any](s *SceneGraph) []T {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+
+	cached, ok := s.typeIndex[key]
+	if !ok {
+		for i := range s.componentCache {
+			if _, ok := s.componentCache[i].(T); ok {
+				cached = append(cached, s.componentCache[i])
+			}
+		}
+
+		s.typeIndex[key] = cached
+	}
+
+	components := make([]T, len(cached))
+	for i := range cached {
+		components[i] = cached[i].(T)
+	}
+
+	return components
+}
+
+// FindComponent returns the first active component of type T in the scene
+// graph, or the zero value of T if there is none.
+func FindComponent[T any](s *SceneGraph) T {
+	var zero T
+
+	if c := FindComponents[T](s); len(c) != 0 {
+		return c[0]
+	}
+
+	return zero
+}
+
+// Find returns the game object at the given slash separated path. Paths are
+// resolved by name from the top level of the graph, so "/level/door" and
+// "level/door" are equivalent. Inactive objects are included. An empty path
+// returns nil.
+func (s *SceneGraph) Find(path string) *GameObject {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return s.root.Find(path)
+}
+
+// FindByName returns all active game objects with the given name.
+func (s *SceneGraph) FindByName(name string) []*GameObject {
+	objects := []*GameObject{}
+
+	for i := range s.active {
+		if s.active[i].Name() == name {
+			objects = append(objects, s.active[i])
+		}
+	}
+
+	return objects
+}
+
+// FindByTag returns all active game objects with the given tag.
+func (s *SceneGraph) FindByTag(tag string) []*GameObject {
+	objects := make([]*GameObject, len(s.tagIndex[tag]))
+	copy(objects, s.tagIndex[tag])
+
+	return objects
+}
+
+// Find returns the descendant at the given slash separated path relative to
+// this game object, or nil if there is none. A path starting with "/" is
+// resolved from the top level of the scene instead.
+func (g *GameObject) Find(path string) *GameObject {
+	if strings.HasPrefix(path, "/") && g.scene != nil {
+		return g.scene.Graph().Find(path)
+	}
+
+	object := g
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+
+		var next *GameObject
+		for i := range object.children {
+			if object.children[i].Name() == name {
+				next = object.children[i]
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		object = next
+	}
+
+	return object
+}
+
+// Path returns the slash separated path of this game object from the top
+// level of its hierarchy.
+func (g *GameObject) Path() string {
+	names := []string{g.Name()}
+
+	for p := g.parent; p != nil; p = p.parent {
+		if g.scene != nil && p == g.scene.Graph().Root() {
+			break
+		}
+		names = append([]string{p.Name()}, names...)
+	}
+
+	return "/" + strings.Join(names, "/")
+}
+
+// Tag returns the tag of this game object.
+func (g *GameObject) Tag() string {
+	return g.tag
+}
+
+// SetTag sets the tag of this game object.
+func (g *GameObject) SetTag(tag string) {
+	if g.tag == tag {
+		return
+	}
+
+	old := g.tag
+	g.tag = tag
+
+	if g.scene != nil && g.scene.Graph() != nil {
+		g.scene.Graph().retag(g, old)
+	}
+}
+
+// CompareTag reports if this game object has the given tag.
+func (g *GameObject) CompareTag(tag string) bool {
+	return g.tag == tag
+}
+
+// retag moves an object between tag index entries after its tag has changed.
+func (s *SceneGraph) retag(g *GameObject, old string) {
+	found := false
+
+	list := s.tagIndex[old]
+	for i := range list {
+		if list[i] == g {
+			s.tagIndex[old] = append(list[:i], list[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	// Untagged objects are not indexed, so live objects receiving their first
+	// tag are added directly. Other objects not in the index are inactive,
+	// they are picked up on update.
+	if (found || (old == "" && g.live)) && g.tag != "" {
+		s.tagIndex[g.tag] = append(s.tagIndex[g.tag], g)
+	}
+}
+
+// rebuildIndexes rebuilds the tag index and clears the type index.
+func (s *SceneGraph) rebuildIndexes() {
+	s.tagIndex = make(map[string][]*GameObject)
+	s.typeIndex = make(map[reflect.Type][]Component)
+
+	for i := range s.active {
+		if tag := s.active[i].tag; tag != "" {
+			s.tagIndex[tag] = append(s.tagIndex[tag], s.active[i])
+		}
+	}
+}
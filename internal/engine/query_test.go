@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestQueryScene adds the hierarchy /level/room/door and /player to the
+// scene graph.
+func newTestQueryScene(t *testing.T) (s *SceneGraph, level, room, door, player *GameObject) {
+	s = newTestScene(t)
+
+	level = NewGameObject("level")
+	room = NewGameObject("room")
+	door = NewGameObject("door")
+	player = NewGameObject("player")
+
+	level.AddChild(room)
+	room.SetParent(level)
+	room.AddChild(door)
+	door.SetParent(room)
+
+	mustAdd(t, s, level, nil)
+	mustAdd(t, s, player, nil)
+
+	return s, level, room, door, player
+}
+
+func TestSceneGraph_Find(t *testing.T) {
+	s, level, room, door, _ := newTestQueryScene(t)
+
+	tests := []struct {
+		from     *GameObject
+		path     string
+		expected *GameObject
+	}{
+		{nil, "/level/room/door", door},
+		{nil, "level/room", room},
+		{nil, "/level/missing", nil},
+		{level, "room/door", door},
+		{level, "", level},
+		{room, "/level", level},
+		{room, "level", nil},
+	}
+
+	for _, test := range tests {
+		var found *GameObject
+		if test.from == nil {
+			found = s.Find(test.path)
+		} else {
+			found = test.from.Find(test.path)
+		}
+
+		if found != test.expected {
+			t.Errorf("Find(%q) = %v, expected %v", test.path, found, test.expected)
+		}
+	}
+
+	if p := door.Path(); p != "/level/room/door" {
+		t.Errorf("path %q, expected /level/room/door", p)
+	}
+
+	for _, path := range []string{"", "/", "//"} {
+		if g := s.Find(path); g != nil {
+			t.Errorf("path %q returned %s, expected nil", path, g.Name())
+		}
+	}
+
+	// Inactive objects are found by path, but not by name.
+	room.SetActive(false)
+	s.Update()
+
+	if s.Find("/level/room/door") != door {
+		t.Error("inactive object not found by path")
+	}
+	if len(s.FindByName("door")) != 0 || len(s.FindByName("level")) != 1 {
+		t.Error("FindByName should only return active objects")
+	}
+}
+
+func TestSceneGraph_FindByTag(t *testing.T) {
+	s, level, room, door, player := newTestQueryScene(t)
+
+	player.SetTag("player")
+	door.SetTag("interactive")
+	room.SetTag("interactive")
+
+	if found := s.FindByTag("interactive"); len(found) != 2 {
+		t.Errorf("%d interactive objects, expected 2", len(found))
+	}
+
+	// Retagged objects move between index entries immediately.
+	door.SetTag("locked")
+
+	if found := s.FindByTag("interactive"); len(found) != 1 || found[0] != room {
+		t.Errorf("interactive objects %v, expected room", found)
+	}
+	if found := s.FindByTag("locked"); len(found) != 1 || found[0] != door {
+		t.Errorf("locked objects %v, expected door", found)
+	}
+
+	level.SetActive(false)
+	s.Update()
+
+	if len(s.FindByTag("interactive")) != 0 || len(s.FindByTag("locked")) != 0 {
+		t.Error("inactive objects found by tag")
+	}
+	if found := s.FindByTag("player"); len(found) != 1 || !player.CompareTag("player") {
+		t.Errorf("player objects %v", found)
+	}
+
+	if err := s.RemoveGameObject(player); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.FindByTag("player")) != 0 {
+		t.Error("removed object found by tag")
+	}
+}
+
+func TestGetComponent(t *testing.T) {
+	s, level, room, door, _ := newTestQueryScene(t)
+
+	roomScript := newTestScript(nil)
+	doorScript := newTestScript(nil)
+	room.AddComponent(roomScript)
+	door.AddComponent(doorScript)
+	s.Update()
+
+	if c := GetComponent[*testScript](room); c != roomScript {
+		t.Errorf("GetComponent = %v, expected room script", c)
+	}
+	if c := GetComponent[ScriptComponent](door); c != doorScript {
+		t.Errorf("GetComponent by interface = %v, expected door script", c)
+	}
+	if c := GetComponent[*testScript](level); c != nil {
+		t.Errorf("GetComponent = %v, expected nil", c)
+	}
+	if c := GetComponent[Transform](level); c != level.Transform() {
+		t.Error("transform not found")
+	}
+
+	// Children are searched depth first, starting with the object itself.
+	if c := GetComponentInChildren[*testScript](level); c != roomScript {
+		t.Errorf("GetComponentInChildren = %v, expected room script", c)
+	}
+	if c := GetComponentInChildren[*testScript](door); c != doorScript {
+		t.Errorf("GetComponentInChildren = %v, expected door script", c)
+	}
+	if c := GetComponentsInChildren[*testScript](level); len(c) != 2 {
+		t.Errorf("%d components in children, expected 2", len(c))
+	}
+	if c := GetComponentInChildren[*Camera](level); c != nil {
+		t.Errorf("GetComponentInChildren = %v, expected nil", c)
+	}
+}
+
+func TestFindComponents(t *testing.T) {
+	s, level, room, door, player := newTestQueryScene(t)
+
+	door.AddComponent(newTestScript(nil))
+	s.Update()
+
+	if c := FindComponents[*testScript](s); len(c) != 1 {
+		t.Errorf("%d scripts, expected 1", len(c))
+	}
+	if _, ok := s.typeIndex[reflect.TypeOf((*testScript)(nil))]; !ok {
+		t.Error("type index not populated")
+	}
+
+	// Adding a component invalidates the index on the next update.
+	player.AddComponent(newTestScript(nil))
+
+	if !s.Dirty() {
+		t.Error("graph not marked dirty by AddComponent")
+	}
+	s.Update()
+
+	if c := FindComponents[*testScript](s); len(c) != 2 {
+		t.Errorf("%d scripts after add, expected 2", len(c))
+	}
+	if c := FindComponents[ScriptComponent](s); len(c) != 2 {
+		t.Errorf("%d script components, expected 2", len(c))
+	}
+
+	// Deactivated and removed objects are dropped from the index.
+	room.SetActive(false)
+	s.Update()
+
+	if c := FindComponents[*testScript](s); len(c) != 1 {
+		t.Errorf("%d scripts after deactivate, expected 1", len(c))
+	}
+
+	if err := s.RemoveGameObject(player); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := FindComponent[*testScript](s); c != nil {
+		t.Errorf("FindComponent = %v, expected nil", c)
+	}
+	for _, c := range FindComponents[Transform](s) {
+		if c != s.Root().Transform() && c != level.Transform() {
+			t.Errorf("transform %v found, expected only root and level", c)
+		}
+	}
+}
@@ -22,21 +22,41 @@ SOFTWARE.
 
 package engine
 
+import "io"
+
 type Scene struct {
+	app              *App
 	environment      *Environment
 	graph            *SceneGraph
+	events           *EventBus
+	scheduler        *Scheduler
 	cameras          []*Camera
+	subScenes        map[string]*GameObject
 	loadFunc         func() error
+	prepareFunc      func(*LoadContext) error
+	loadOp           *SceneLoadOperation
 	onActivateFunc   func()
 	onDeactivateFunc func()
 	name             string
 	loaded           bool
+	renderCovered    bool
+	updateCovered    bool
 }
 
 func (s *Scene) Setup() {
 	s.graph = NewSceneGraph(s)
 }
 
+// App returns the App the scene is registered with. Scenes which are not
+// registered with an App use the current App.
+func (s *Scene) App() *App {
+	if s.app != nil {
+		return s.app
+	}
+
+	return CurrentApp()
+}
+
 // Name returns the name of this scene.
 func (s *Scene) Name() string {
 	return s.name
@@ -62,11 +82,25 @@ func (s *Scene) Load() error {
 		return nil
 	}
 
+	if s.prepareFunc != nil {
+		if err := s.prepareFunc(&LoadContext{app: s.App()}); err != nil {
+			return err
+		}
+	}
+
+	return s.finishLoad()
+}
+
+// finishLoad builds the scene graph and runs the load function. It must be
+// called on the main thread.
+func (s *Scene) finishLoad() error {
 	s.graph = NewSceneGraph(s)
 	s.environment = NewEnvironment()
 
 	if s.loadFunc != nil {
-		s.loadFunc()
+		if err := s.loadFunc(); err != nil {
+			return err
+		}
 	}
 
 	s.loaded = true
@@ -74,6 +108,32 @@ func (s *Scene) Load() error {
 	return nil
 }
 
+// RenderWhenCovered reports if the scene is rendered while other scenes are
+// pushed on top of it.
+func (s *Scene) RenderWhenCovered() bool {
+	return s.renderCovered
+}
+
+// SetRenderWhenCovered sets if the scene is rendered while other scenes are
+// pushed on top of it. Covered scenes are rendered first and the scenes above
+// are blended over them by alpha, so their cameras should clear to a
+// transparent color.
+func (s *Scene) SetRenderWhenCovered(render bool) {
+	s.renderCovered = render
+}
+
+// UpdateWhenCovered reports if the scene is updated while other scenes are
+// pushed on top of it.
+func (s *Scene) UpdateWhenCovered() bool {
+	return s.updateCovered
+}
+
+// SetUpdateWhenCovered sets if the scene is updated while other scenes are
+// pushed on top of it.
+func (s *Scene) SetUpdateWhenCovered(update bool) {
+	s.updateCovered = update
+}
+
 // Loaded reports if the scene has been loaded.
 func (s *Scene) Loaded() bool {
 	return s.loaded
@@ -85,15 +145,8 @@ func (s *Scene) Graph() *SceneGraph {
 }
 
 func (s *Scene) OnSceneGraphUpdate() {
-	s.cameras = s.cameras[:0]
-
 	// Update renderer cache.
-	components := s.graph.Components()
-	for i := range components {
-		if c, ok := components[i].(*Camera); ok {
-			s.cameras = append(s.cameras, c)
-		}
-	}
+	s.cameras = FindComponents[*Camera](s.graph)
 }
 
 // Graph gets the SceneGraph for this Scene.
@@ -105,6 +158,13 @@ func (s *Scene) SetLoadFunc(fn func() error) {
 	s.loadFunc = fn
 }
 
+// SetPrepareFunc sets a function which runs before the load function. When the
+// scene is loaded with App.LoadSceneAsync, it runs on a worker goroutine, so it
+// should read and decode assets and leave OpenGL work to LoadContext.Main.
+func (s *Scene) SetPrepareFunc(fn func(*LoadContext) error) {
+	s.prepareFunc = fn
+}
+
 func (s *Scene) SetOnActivateFunc(fn func()) {
 	s.graph.notifyListeners()
 
@@ -115,11 +175,97 @@ func (s *Scene) SetOnDeactivateFunc(fn func()) {
 	s.onDeactivateFunc = fn
 }
 
+// Metadata captures the GameObject hierarchy of this scene.
+func (s *Scene) Metadata() (*SceneMetadata, error) {
+	m := &SceneMetadata{
+		Version: SceneFileVersion,
+		Name:    s.name,
+		Objects: []*GameObjectMetadata{},
+	}
+
+	if s.graph == nil {
+		return m, nil
+	}
+
+	objects := s.graph.Children(s.graph.Root())
+	for i := range objects {
+		om, err := NewGameObjectMetadata(objects[i])
+		if err != nil {
+			return nil, err
+		}
+
+		m.Objects = append(m.Objects, om)
+	}
+
+	return m, nil
+}
+
+// LoadMetadata adds the GameObject hierarchy described by the metadata to
+// this scene.
+func (s *Scene) LoadMetadata(m *SceneMetadata) error {
+	for i := range m.Objects {
+		object, err := NewGameObjectFromMetadata(m.Objects[i])
+		if err != nil {
+			return err
+		}
+
+		if err := s.graph.AddGameObject(object, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save writes the GameObject hierarchy of this scene to the writer.
+func (s *Scene) Save(w io.Writer, format SceneFormat) error {
+	m, err := s.Metadata()
+	if err != nil {
+		return err
+	}
+
+	return EncodeScene(w, m, format)
+}
+
+// SaveToFile writes the GameObject hierarchy of this scene to file.
+func (s *Scene) SaveToFile(filename string, format SceneFormat) error {
+	m, err := s.Metadata()
+	if err != nil {
+		return err
+	}
+
+	return SaveSceneToFile(filename, m, format)
+}
+
 func NewScene(name string) *Scene {
 	s := &Scene{
-		name:    name,
-		cameras: []*Camera{},
+		name:      name,
+		events:    NewEventBus(),
+		scheduler: NewScheduler(),
+		cameras:   []*Camera{},
+		subScenes: make(map[string]*GameObject),
 	}
 
 	return s
 }
+
+// NewSceneFromAsset creates a new scene which is populated from the scene
+// asset with the given name when it is loaded.
+func NewSceneFromAsset(name, asset string) *Scene {
+	s := NewScene(name)
+	s.SetLoadFunc(func() error {
+		h, err := handlerOf[*SceneHandler](s.App().Asset(), AssetNameScene)
+		if err != nil {
+			return err
+		}
+
+		f, err := h.Get(asset)
+		if err != nil {
+			return err
+		}
+
+		return s.LoadMetadata(f.Metadata())
+	})
+
+	return s
+}
@@ -37,6 +37,10 @@ type ControlExposure struct {
 	tonemapper *effects.Tonemapper
 }
 
+func init() {
+	engine.RegisterComponentType("ControlExposure", &ControlExposure{}, decodeControlExposure)
+}
+
 func NewControlExposure() *ControlExposure {
 	c := &ControlExposure{}
 
@@ -47,14 +51,7 @@ func NewControlExposure() *ControlExposure {
 }
 
 func ControlExposureComponent(e *engine.GameObject) *ControlExposure {
-	c := e.Components()
-	for i := range c {
-		if ct, ok := c[i].(*ControlExposure); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*ControlExposure](e)
 }
 
 func (c *ControlExposure) SetTonemapper(t *effects.Tonemapper) {
@@ -72,3 +69,16 @@ func (c *ControlExposure) Update() {
 		c.tonemapper.SetExposure(c.tonemapper.Exposure() + 0.05)
 	}
 }
+
+// CloneComponent returns a copy of the ControlExposure which controls the same
+// tonemapper.
+func (c *ControlExposure) CloneComponent() (engine.Component, error) {
+	n := NewControlExposure()
+	n.tonemapper = c.tonemapper
+
+	return n, nil
+}
+
+func decodeControlExposure(properties []byte) (engine.Component, error) {
+	return NewControlExposure(), nil
+}
@@ -23,6 +23,7 @@ SOFTWARE.
 package scene
 
 import (
+	"encoding/json"
 	"math"
 
 	"github.com/go-gl/glfw/v3.2/glfw"
@@ -60,6 +61,16 @@ type ControlOrbit struct {
 	mouseDelta mgl32.Vec2
 }
 
+type controlOrbitProperties struct {
+	Radial float64 `json:"radial"`
+	Phi    float64 `json:"phi"`
+	Theta  float64 `json:"theta"`
+}
+
+func init() {
+	engine.RegisterComponentType("ControlOrbit", &ControlOrbit{}, decodeControlOrbit)
+}
+
 func NewControlOrbit() *ControlOrbit {
 	c := &ControlOrbit{
 		radial:  4,
@@ -80,14 +91,7 @@ func NewControlOrbit() *ControlOrbit {
 }
 
 func ControlOrbitComponent(g *engine.GameObject) *ControlOrbit {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*ControlOrbit); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*ControlOrbit](g)
 }
 
 func (c *ControlOrbit) move() {
@@ -214,6 +218,50 @@ func (c *ControlOrbit) LateUpdate() {
 	}
 }
 
+// CloneComponent returns a copy of the ControlOrbit which orbits the same
+// target.
+func (c *ControlOrbit) CloneComponent() (engine.Component, error) {
+	n := NewControlOrbit()
+	n.Target = c.Target
+	n.radial, n.radialL, n.radialC = c.radial, c.radialL, c.radialC
+	n.phi, n.phiL, n.phiC = c.phi, c.phiL, c.phiC
+	n.theta, n.thetaL, n.thetaC = c.theta, c.thetaL, c.thetaC
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the ControlOrbit.
+// The target is not saved and must be assigned after loading.
+func (c *ControlOrbit) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&controlOrbitProperties{
+		Radial: c.radial,
+		Phi:    c.phi,
+		Theta:  c.theta,
+	})
+}
+
+func decodeControlOrbit(properties []byte) (engine.Component, error) {
+	c := NewControlOrbit()
+
+	if properties != nil {
+		p := &controlOrbitProperties{
+			Radial: c.radial,
+			Phi:    c.phi,
+			Theta:  c.theta,
+		}
+
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+
+		c.radial, c.radialC = p.Radial, p.Radial
+		c.phi, c.phiC = p.Phi, p.Phi
+		c.theta, c.thetaC = p.Theta, p.Theta
+	}
+
+	return c, nil
+}
+
 func sphericalToCartesian(radial, theta, phi float64) mgl32.Vec3 {
 	st, ct := math.Sincos(theta)
 	sp, cp := math.Sincos(phi)
@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"encoding/json"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sirupsen/logrus"
+
+	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/instance"
+)
+
+type chunkState int
+
+const (
+	chunkUnloaded chunkState = iota
+	chunkLoading
+	chunkLoaded
+)
+
+// StreamChunk is a part of a level which is streamed in as a sub-scene.
+type StreamChunk struct {
+	Name   string     `json:"name"`
+	File   string     `json:"file"`
+	Center mgl32.Vec3 `json:"center"`
+}
+
+type levelStreamerProperties struct {
+	Chunks       []StreamChunk `json:"chunks"`
+	LoadRadius   float32       `json:"load_radius"`
+	UnloadRadius float32       `json:"unload_radius"`
+}
+
+// LevelStreamer loads scene file chunks additively when the target comes
+// within LoadRadius of their center, and unloads them when it moves further
+// than UnloadRadius away. Files are read and decoded on worker goroutines.
+type LevelStreamer struct {
+	engine.BaseScriptComponent
+
+	Target engine.Transform
+
+	chunks       []StreamChunk
+	states       map[string]chunkState
+	loads        map[string]int
+	loadRadius   float32
+	unloadRadius float32
+}
+
+// loadChunkFile reads the scene file of a chunk on a worker goroutine.
+var loadChunkFile = engine.LoadSceneFromFile
+
+func init() {
+	engine.RegisterComponentType("LevelStreamer", &LevelStreamer{}, decodeLevelStreamer)
+}
+
+// NewLevelStreamer creates a new LevelStreamer. The unload radius should be
+// larger than the load radius, so chunks at the border do not thrash.
+func NewLevelStreamer(loadRadius, unloadRadius float32) *LevelStreamer {
+	c := &LevelStreamer{
+		chunks:       []StreamChunk{},
+		states:       make(map[string]chunkState),
+		loads:        make(map[string]int),
+		loadRadius:   loadRadius,
+		unloadRadius: unloadRadius,
+	}
+
+	c.SetName("LevelStreamer")
+	instance.MustAssign(c)
+
+	return c
+}
+
+func LevelStreamerComponent(g *engine.GameObject) *LevelStreamer {
+	return engine.GetComponent[*LevelStreamer](g)
+}
+
+// AddChunk adds a chunk to be streamed.
+func (c *LevelStreamer) AddChunk(chunk StreamChunk) {
+	c.chunks = append(c.chunks, chunk)
+}
+
+// Chunks returns the chunks of the streamer.
+func (c *LevelStreamer) Chunks() []StreamChunk {
+	return c.chunks
+}
+
+// Loaded reports if the chunk with the given name is loaded.
+func (c *LevelStreamer) Loaded(name string) bool {
+	return c.states[name] == chunkLoaded
+}
+
+func (c *LevelStreamer) Update() {
+	if c.Target == nil || c.GameObject() == nil || c.GameObject().Scene() == nil {
+		return
+	}
+
+	position := c.Target.ActiveMatrix().Col(3).Vec3()
+
+	for i := range c.chunks {
+		chunk := c.chunks[i]
+		distance := chunk.Center.Sub(position).Len()
+
+		switch c.states[chunk.Name] {
+		case chunkUnloaded:
+			if distance <= c.loadRadius {
+				c.load(chunk)
+			}
+		case chunkLoading:
+			if distance > c.unloadRadius {
+				// The pending load is discarded when it completes.
+				c.states[chunk.Name] = chunkUnloaded
+			}
+		case chunkLoaded:
+			if distance > c.unloadRadius {
+				c.unload(chunk)
+			}
+		}
+	}
+}
+
+// OnDestroy unloads all chunks loaded by the streamer.
+func (c *LevelStreamer) OnDestroy() {
+	for i := range c.chunks {
+		if c.states[c.chunks[i].Name] == chunkLoaded {
+			c.unload(c.chunks[i])
+		}
+	}
+}
+
+func (c *LevelStreamer) load(chunk StreamChunk) {
+	c.states[chunk.Name] = chunkLoading
+
+	// A chunk which leaves and re-enters the load radius while loading starts
+	// a new load, the earlier one is discarded when it completes.
+	c.loads[chunk.Name]++
+	load := c.loads[chunk.Name]
+
+	scene := c.GameObject().Scene()
+	app := c.GameObject().App()
+
+	go func() {
+		m, err := loadChunkFile(chunk.File)
+
+		app.RunOnMain(func() {
+			if c.states[chunk.Name] != chunkLoading || c.loads[chunk.Name] != load {
+				return
+			}
+			if c.GameObject() == nil || c.GameObject().Destroyed() {
+				return
+			}
+
+			if err != nil {
+				logrus.Errorf("level streamer: chunk %s: %s", chunk.Name, err)
+				c.states[chunk.Name] = chunkUnloaded
+				return
+			}
+
+			if _, err := scene.LoadAdditive(chunk.Name, m); err != nil {
+				logrus.Errorf("level streamer: chunk %s: %s", chunk.Name, err)
+				c.states[chunk.Name] = chunkUnloaded
+				return
+			}
+
+			c.states[chunk.Name] = chunkLoaded
+		})
+	}()
+}
+
+func (c *LevelStreamer) unload(chunk StreamChunk) {
+	c.states[chunk.Name] = chunkUnloaded
+
+	if err := c.GameObject().Scene().UnloadAdditive(chunk.Name); err != nil {
+		logrus.Errorf("level streamer: chunk %s: %s", chunk.Name, err)
+	}
+}
+
+// CloneComponent returns a copy of the LevelStreamer which streams the same
+// chunks around the same target. Loaded chunks belong to the original.
+func (c *LevelStreamer) CloneComponent() (engine.Component, error) {
+	n := NewLevelStreamer(c.loadRadius, c.unloadRadius)
+	n.Target = c.Target
+	n.chunks = append(n.chunks, c.chunks...)
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the LevelStreamer.
+// The target is not saved and must be assigned after loading.
+func (c *LevelStreamer) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&levelStreamerProperties{
+		Chunks:       c.chunks,
+		LoadRadius:   c.loadRadius,
+		UnloadRadius: c.unloadRadius,
+	})
+}
+
+func decodeLevelStreamer(properties []byte) (engine.Component, error) {
+	p := &levelStreamerProperties{}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	c := NewLevelStreamer(p.LoadRadius, p.UnloadRadius)
+	for i := range p.Chunks {
+		c.AddChunk(p.Chunks[i])
+	}
+
+	return c, nil
+}
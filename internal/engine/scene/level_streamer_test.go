@@ -0,0 +1,224 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+// setupStreamingScene pushes a scene with a LevelStreamer following a target,
+// and a single chunk at the origin. It returns the target transform.
+func setupStreamingScene(t *testing.T, a *engine.App) (*engine.Scene, *LevelStreamer, engine.Transform) {
+	object, err := engine.NewGameObjectMetadata(engine.NewGameObject("tree"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "chunk.json")
+	m := &engine.SceneMetadata{
+		Version: engine.SceneFileVersion,
+		Name:    "chunk",
+		Objects: []*engine.GameObjectMetadata{object},
+	}
+	if err := engine.SaveSceneToFile(filename, m, engine.SceneFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	streamer := NewLevelStreamer(10, 20)
+	streamer.AddChunk(StreamChunk{Name: "chunk", File: filename})
+
+	target := engine.NewGameObject("target")
+	target.Transform().SetPosition(mgl32.Vec3{15, 0, 0})
+	streamer.Target = target.Transform()
+
+	s := engine.NewScene("streaming")
+	s.SetLoadFunc(func() error {
+		g := engine.NewGameObject("streamer")
+		g.AddComponent(streamer)
+
+		if err := s.Graph().AddGameObject(target, nil); err != nil {
+			return err
+		}
+
+		return s.Graph().AddGameObject(g, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("streaming"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.PopScene() })
+
+	return s, streamer, target.Transform()
+}
+
+// runFrames runs single tick frames until done returns true.
+func runFrames(t *testing.T, a *engine.App, done func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the level streamer")
+		}
+
+		if err := a.RunTicks(1); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// settle runs a few frames, giving completed loads time to reach the main
+// thread.
+func settle(t *testing.T, a *engine.App) {
+	for i := 0; i < 10; i++ {
+		if err := a.RunTicks(1); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockChunkLoads makes chunk loads wait until their gate is closed. Gates
+// are sent on the returned channel in the order loads start, and a value is
+// sent on done when a load returns.
+func blockChunkLoads(t *testing.T) (gates chan chan struct{}, done chan struct{}) {
+	gates = make(chan chan struct{}, 4)
+	done = make(chan struct{}, 4)
+
+	t.Cleanup(func() { loadChunkFile = engine.LoadSceneFromFile })
+	loadChunkFile = func(filename string) (*engine.SceneMetadata, error) {
+		defer func() { done <- struct{}{} }()
+
+		gate := make(chan struct{})
+		gates <- gate
+		<-gate
+
+		return engine.LoadSceneFromFile(filename)
+	}
+
+	return gates, done
+}
+
+func nextGate(t *testing.T, gates chan chan struct{}) chan struct{} {
+	select {
+	case gate := <-gates:
+		return gate
+	case <-time.After(5 * time.Second):
+		t.Fatal("chunk load not started")
+	}
+
+	return nil
+}
+
+func TestLevelStreamer_Radius(t *testing.T) {
+	a := setupTestApp(t)
+	s, streamer, target := setupStreamingScene(t, a)
+
+	// Outside the load radius nothing is loaded.
+	settle(t, a)
+
+	if streamer.Loaded("chunk") || s.SubScene("chunk") != nil {
+		t.Fatal("chunk loaded outside the load radius")
+	}
+
+	target.SetPosition(mgl32.Vec3{10, 0, 0})
+	runFrames(t, a, func() bool { return streamer.Loaded("chunk") })
+
+	if root := s.SubScene("chunk"); root == nil || root.Find("tree") == nil {
+		t.Fatal("chunk objects not added to the scene")
+	}
+
+	// Between the radii the chunk stays loaded.
+	target.SetPosition(mgl32.Vec3{20, 0, 0})
+	settle(t, a)
+
+	if !streamer.Loaded("chunk") {
+		t.Fatal("chunk unloaded inside the unload radius")
+	}
+
+	target.SetPosition(mgl32.Vec3{21, 0, 0})
+	if err := a.RunTicks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if streamer.Loaded("chunk") || s.SubScene("chunk") != nil {
+		t.Error("chunk not unloaded outside the unload radius")
+	}
+}
+
+func TestLevelStreamer_DiscardPendingLoad(t *testing.T) {
+	gates, done := blockChunkLoads(t)
+
+	a := setupTestApp(t)
+	s, streamer, target := setupStreamingScene(t, a)
+
+	target.SetPosition(mgl32.Vec3{})
+	settle(t, a)
+	gate := nextGate(t, gates)
+
+	// Leaving the unload radius while loading discards the load.
+	target.SetPosition(mgl32.Vec3{30, 0, 0})
+	settle(t, a)
+	close(gate)
+	<-done
+	settle(t, a)
+
+	if streamer.Loaded("chunk") || s.SubScene("chunk") != nil {
+		t.Fatal("discarded load added the chunk")
+	}
+
+	// Re-entering while the first load is pending starts a new load, and
+	// only that one is used.
+	target.SetPosition(mgl32.Vec3{})
+	settle(t, a)
+	first := nextGate(t, gates)
+	target.SetPosition(mgl32.Vec3{30, 0, 0})
+	settle(t, a)
+	target.SetPosition(mgl32.Vec3{})
+	settle(t, a)
+	second := nextGate(t, gates)
+
+	close(first)
+	<-done
+	settle(t, a)
+
+	if streamer.Loaded("chunk") || s.SubScene("chunk") != nil {
+		t.Fatal("superseded load added the chunk")
+	}
+
+	close(second)
+	runFrames(t, a, func() bool { return streamer.Loaded("chunk") })
+
+	if s.SubScene("chunk") == nil {
+		t.Error("chunk not added to the scene")
+	}
+}
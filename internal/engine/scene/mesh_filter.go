@@ -23,7 +23,10 @@ SOFTWARE.
 package scene
 
 import (
+	"encoding/json"
+
 	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/asset/mesh"
 	"github.com/haakenlabs/forge/internal/engine/system/instance"
 )
 
@@ -32,6 +35,18 @@ type MeshFilter struct {
 	engine.BaseComponent
 
 	mesh *engine.Mesh
+
+	// meshName is the mesh asset name of a filter decoded by a headless App,
+	// where meshes are not loaded.
+	meshName string
+}
+
+type meshFilterProperties struct {
+	Mesh string `json:"mesh"`
+}
+
+func init() {
+	engine.RegisterComponentType("MeshFilter", &MeshFilter{}, decodeMeshFilter)
 }
 
 
@@ -49,14 +64,7 @@ func NewMeshFilter(mesh *engine.Mesh) *MeshFilter {
 
 // MeshFilterComponent gets the first occurrence of MeshFilter from the entity.
 func MeshFilterComponent(g *engine.GameObject) *MeshFilter {
-	c := g.Components()
-	for i := range c {
-		if ct, ok := c[i].(*MeshFilter); ok {
-			return ct
-		}
-	}
-
-	return nil
+	return engine.GetComponent[*MeshFilter](g)
 }
 
 // Mesh gets the Mesh associated with this MeshFilter.
@@ -68,3 +76,51 @@ func (m *MeshFilter) Mesh() *engine.Mesh {
 func (m *MeshFilter) SetMesh(mesh *engine.Mesh) {
 	m.mesh = mesh
 }
+
+// CloneComponent returns a copy of the MeshFilter. The mesh is shared with the
+// original.
+func (m *MeshFilter) CloneComponent() (engine.Component, error) {
+	n := NewMeshFilter(m.mesh)
+	n.meshName = m.meshName
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the MeshFilter. The
+// mesh is stored by asset name.
+func (m *MeshFilter) EncodeProperties() ([]byte, error) {
+	p := &meshFilterProperties{Mesh: m.meshName}
+	if m.mesh != nil {
+		p.Mesh = m.mesh.Name()
+	}
+
+	return json.Marshal(p)
+}
+
+func decodeMeshFilter(properties []byte) (engine.Component, error) {
+	p := &meshFilterProperties{}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Mesh == "" {
+		return NewMeshFilter(nil), nil
+	}
+
+	if a := engine.CurrentApp(); a != nil && a.Headless() {
+		f := NewMeshFilter(nil)
+		f.meshName = p.Mesh
+
+		return f, nil
+	}
+
+	m, err := mesh.Get(p.Mesh)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMeshFilter(m), nil
+}
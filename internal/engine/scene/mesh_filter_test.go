@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"testing"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+// setupTestApp sets up a headless App for a single test.
+func setupTestApp(t *testing.T) *engine.App {
+	a := engine.NewApp(&engine.AppConfig{Name: "test", Headless: true})
+
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(a.Teardown)
+
+	return a
+}
+
+func TestDecodeMeshComponents_Headless(t *testing.T) {
+	setupTestApp(t)
+
+	c, err := engine.DecodeComponent("MeshFilter", []byte(`{"mesh": "cube"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := c.(*MeshFilter); f.Mesh() != nil {
+		t.Error("headless mesh filter loaded a mesh")
+	}
+	if data, err := c.(*MeshFilter).EncodeProperties(); err != nil || string(data) != `{"mesh":"cube"}` {
+		t.Errorf("mesh filter properties %s, %v", data, err)
+	}
+
+	c, err = engine.DecodeComponent("MeshRenderer", []byte(`{"shader": "standard", "cull_face": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.(*MeshRenderer)
+	if r.GetMaterial() != nil {
+		t.Error("headless mesh renderer created a material")
+	}
+	if p := r.properties(); p.Shader != "standard" || !p.CullFace {
+		t.Errorf("mesh renderer properties %+v", p)
+	}
+}
+
+func TestCloneComponents(t *testing.T) {
+	setupTestApp(t)
+
+	material := engine.NewMaterial()
+	material.SetProperty("f_roughness", float32(0.5))
+
+	r := NewMeshRenderer()
+	r.SetMaterial(material)
+	r.SetMorphWeight("smile", 0.5)
+	r.wireframe = true
+
+	c, err := r.CloneComponent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr := c.(*MeshRenderer)
+	if cr.ID() == r.ID() || !cr.wireframe || cr.MorphWeight("smile") != 0.5 {
+		t.Errorf("mesh renderer not copied: %+v", cr)
+	}
+	if cr.GetMaterial() == nil || cr.GetMaterial() == material {
+		t.Fatal("material not copied")
+	}
+	if v, _ := cr.GetMaterial().Property("f_roughness"); v != float32(0.5) {
+		t.Errorf("material property %v, expected 0.5", v)
+	}
+
+	// The copy has its own material properties and morph weights.
+	cr.GetMaterial().SetProperty("f_roughness", float32(1))
+	cr.SetMorphWeight("smile", 1)
+	if v, _ := material.Property("f_roughness"); v != float32(0.5) || r.MorphWeight("smile") != 0.5 {
+		t.Error("copy shares state with the original")
+	}
+
+	target := engine.NewTransform()
+	o := NewControlOrbit()
+	o.Target = target
+	o.radial = 8
+
+	c, err = o.CloneComponent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co := c.(*ControlOrbit); co.Target != target || co.radial != 8 {
+		t.Errorf("control orbit not copied: target %v radial %v", co.Target, co.radial)
+	}
+}
+
+func TestMeshRenderer_DestroyReleasesMorphs(t *testing.T) {
+	setupTestApp(t)
+
+	mesh := engine.NewMesh()
+	r := NewMeshRenderer()
+	r.morphers = map[*engine.Mesh]*engine.MeshMorpher{mesh: engine.NewMeshMorpher(mesh)}
+
+	g := engine.NewGameObject("morphed")
+	g.AddComponent(r)
+	g.Destroy()
+
+	if r.morphers != nil {
+		t.Error("morphers not released when the renderer was destroyed")
+	}
+}
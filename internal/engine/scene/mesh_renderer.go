@@ -23,22 +23,70 @@ SOFTWARE.
 package scene
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/go-gl/gl/v4.3-core/gl"
+	"github.com/sirupsen/logrus"
 
 	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/asset/shader"
 	"github.com/haakenlabs/forge/internal/engine/system/instance"
 )
 
 type MeshRenderer struct {
 	Renderer
 
-	material   *engine.Material
-	cullFace   bool
-	depthWrite bool
-	wireframe  bool
+	material     *engine.Material
+	shaderName   string
+	morphWeights map[string]float32
+	morphers     map[*engine.Mesh]*engine.MeshMorpher
+	cullFace     bool
+	depthWrite   bool
+	wireframe    bool
 }
 
 var _ engine.Renderer = &MeshRenderer{}
+var _ engine.ComponentDestroyer = &MeshRenderer{}
+
+type meshRendererProperties struct {
+	Shader     string `json:"shader"`
+	CullFace   bool   `json:"cull_face"`
+	DepthWrite bool   `json:"depth_write"`
+	Wireframe  bool   `json:"wireframe"`
+
+	Morphs map[string]float32 `json:"morphs,omitempty"`
+}
+
+// morphRenderer is implemented by renderers with morph target weights.
+type morphRenderer interface {
+	MorphWeight(name string) float32
+	SetMorphWeight(name string, weight float32)
+}
+
+func init() {
+	engine.RegisterComponentType("MeshRenderer", &MeshRenderer{}, decodeMeshRenderer)
+	engine.RegisterAnimationPropertyResolver("MeshRenderer", morphWeightProperty)
+}
+
+// morphWeightProperty resolves "morph.<name>" to the weight of the morph
+// target with that name.
+func morphWeightProperty(property string) (*engine.AnimationProperty, bool) {
+	name, ok := strings.CutPrefix(property, "morph.")
+	if !ok || name == "" {
+		return nil, false
+	}
+
+	return &engine.AnimationProperty{
+		Size: 1,
+		Get: func(c engine.Component) []float32 {
+			return []float32{c.(morphRenderer).MorphWeight(name)}
+		},
+		Set: func(c engine.Component, v []float32) {
+			c.(morphRenderer).SetMorphWeight(name, v[0])
+		},
+	}, true
+}
 
 func NewMeshRenderer() *MeshRenderer {
 	c := &MeshRenderer{
@@ -62,7 +110,82 @@ func (m *MeshRenderer) GetMaterial() *engine.Material {
 	return m.material
 }
 
+// MorphWeight returns the weight of the morph target with the given name.
+func (m *MeshRenderer) MorphWeight(name string) float32 {
+	return m.morphWeights[name]
+}
+
+// SetMorphWeight sets the weight of the morph target with the given name on
+// all meshes of the GameObject which have it. Morph targets are blended on the
+// CPU into a copy of the mesh owned by the renderer.
+func (m *MeshRenderer) SetMorphWeight(name string, weight float32) {
+	if m.morphWeights == nil {
+		m.morphWeights = make(map[string]float32)
+	}
+
+	if weight == 0 {
+		delete(m.morphWeights, name)
+	} else {
+		m.morphWeights[name] = weight
+	}
+}
+
+// ReleaseMorphs frees the morphed copies of the meshes. They are created again
+// when a morph target weight is set and the renderer is drawn.
+func (m *MeshRenderer) ReleaseMorphs() {
+	for _, morpher := range m.morphers {
+		morpher.Release()
+	}
+
+	m.morphers = nil
+}
+
+// OnDestroy releases the morphed copies of the meshes.
+func (m *MeshRenderer) OnDestroy() {
+	m.ReleaseMorphs()
+}
+
+// morphed returns the mesh with the morph target weights of the renderer
+// applied.
+func (m *MeshRenderer) morphed(mesh *engine.Mesh) *engine.Mesh {
+	targets := mesh.MorphTargets()
+	if len(targets) == 0 {
+		return mesh
+	}
+
+	morpher, ok := m.morphers[mesh]
+	if !ok {
+		if len(m.morphWeights) == 0 {
+			return mesh
+		}
+		if m.morphers == nil {
+			m.morphers = make(map[*engine.Mesh]*engine.MeshMorpher)
+		}
+
+		morpher = engine.NewMeshMorpher(mesh)
+		m.morphers[mesh] = morpher
+	}
+
+	for i := range targets {
+		morpher.SetWeight(i, m.morphWeights[targets[i].Name])
+	}
+
+	result, err := morpher.Mesh()
+	if err != nil {
+		logrus.Error("mesh renderer: ", err)
+		return mesh
+	}
+
+	return result
+}
+
 func (m *MeshRenderer) Render(camera *engine.Camera) {
+	m.render(camera, m.RenderShader)
+}
+
+// render binds the material for the render path of the camera and draws with
+// its shader.
+func (m *MeshRenderer) render(camera *engine.Camera, draw func(*engine.Shader, *engine.Camera)) {
 	if !m.enabled && m.material == nil {
 		return
 	}
@@ -77,7 +200,7 @@ func (m *MeshRenderer) Render(camera *engine.Camera) {
 		}
 	}
 
-	m.RenderShader(m.material.Shader(), camera)
+	draw(m.material.Shader(), camera)
 
 	m.material.Unbind()
 }
@@ -87,22 +210,12 @@ func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera
 		return
 	}
 
-	// FIXME: Move this somewhere out of the render loop
-	meshes := []*engine.Mesh{}
-	components := m.GameObject().Components()
-	for i := range components {
-		if meshFilter, ok := components[i].(*MeshFilter); ok {
-			if mesh := meshFilter.Mesh(); mesh != nil {
-				meshes = append(meshes, mesh)
-			}
-		}
-	}
-
+	meshes := m.meshes()
 	if len(meshes) == 0 {
 		return
 	}
 
-	shader.SetUniform("v_model_matrix", m.GetTransform().ActiveMatrix())
+	shader.SetUniform("v_model_matrix", m.GetTransform().RenderMatrix())
 	shader.SetUniform("v_view_matrix", camera.ViewMatrix())
 	shader.SetUniform("v_projection_matrix", camera.ProjectionMatrix())
 	shader.SetUniform("v_normal_matrix", camera.NormalMatrix())
@@ -119,15 +232,16 @@ func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera
 	}
 
 	for i := range meshes {
-		meshes[i].Bind()
+		mesh := m.morphed(meshes[i])
+		mesh.Bind()
 
-		if meshes[i].Indexed() {
-			gl.DrawElements(gl.TRIANGLES, int32(len(meshes[i].Triangles())), gl.UNSIGNED_INT, nil)
+		if mesh.Indexed() {
+			gl.DrawElements(gl.TRIANGLES, int32(len(mesh.Triangles())), gl.UNSIGNED_INT, nil)
 		} else {
-			gl.DrawArrays(gl.TRIANGLES, 0, int32(len(meshes[i].Vertices())))
+			gl.DrawArrays(gl.TRIANGLES, 0, int32(len(mesh.Vertices())))
 		}
 
-		meshes[i].Unbind()
+		mesh.Unbind()
 
 	}
 
@@ -142,6 +256,22 @@ func (m *MeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera
 	}
 }
 
+// meshes returns the meshes of the MeshFilters of the GameObject.
+func (m *MeshRenderer) meshes() []*engine.Mesh {
+	// FIXME: Move this somewhere out of the render loop
+	meshes := []*engine.Mesh{}
+	components := m.GameObject().Components()
+	for i := range components {
+		if meshFilter, ok := components[i].(*MeshFilter); ok {
+			if mesh := meshFilter.Mesh(); mesh != nil {
+				meshes = append(meshes, mesh)
+			}
+		}
+	}
+
+	return meshes
+}
+
 func (m *MeshRenderer) CullFaceEnabled() bool {
 	return m.cullFace
 }
@@ -173,3 +303,105 @@ func (m *MeshRenderer) SupportsDeferred() bool {
 
 	return false
 }
+
+// CloneComponent returns a copy of the MeshRenderer with its own material and
+// morph weights. Morphed meshes are created again when the copy first renders.
+func (m *MeshRenderer) CloneComponent() (engine.Component, error) {
+	n := NewMeshRenderer()
+	m.copyTo(n)
+
+	return n, nil
+}
+
+// copyTo copies the renderer state shared by all mesh renderers to n.
+func (m *MeshRenderer) copyTo(n *MeshRenderer) {
+	n.enabled = m.enabled
+	n.shaderName = m.shaderName
+	n.cullFace = m.cullFace
+	n.depthWrite = m.depthWrite
+	n.wireframe = m.wireframe
+
+	if m.material != nil {
+		n.material = m.material.Clone()
+	}
+
+	for name, weight := range m.morphWeights {
+		n.SetMorphWeight(name, weight)
+	}
+}
+
+// EncodeProperties returns the JSON encoded properties of the MeshRenderer.
+// The material is stored by shader asset name.
+func (m *MeshRenderer) EncodeProperties() ([]byte, error) {
+	return json.Marshal(m.properties())
+}
+
+func decodeMeshRenderer(properties []byte) (engine.Component, error) {
+	p := &meshRendererProperties{
+		CullFace:   true,
+		DepthWrite: true,
+	}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	m := NewMeshRenderer()
+	if err := m.applyProperties(p); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *MeshRenderer) properties() *meshRendererProperties {
+	p := &meshRendererProperties{
+		CullFace:   m.cullFace,
+		DepthWrite: m.depthWrite,
+		Wireframe:  m.wireframe,
+	}
+	if len(m.morphWeights) != 0 {
+		p.Morphs = make(map[string]float32, len(m.morphWeights))
+		for name, weight := range m.morphWeights {
+			p.Morphs[name] = weight
+		}
+	}
+	if m.material != nil && m.material.Shader() != nil {
+		p.Shader = m.material.Shader().Name()
+	} else {
+		p.Shader = m.shaderName
+	}
+
+	return p
+}
+
+func (m *MeshRenderer) applyProperties(p *meshRendererProperties) error {
+	m.cullFace = p.CullFace
+	m.depthWrite = p.DepthWrite
+	m.wireframe = p.Wireframe
+
+	for name, weight := range p.Morphs {
+		m.SetMorphWeight(name, weight)
+	}
+
+	// Shaders are not loaded by headless Apps, so only the name is kept.
+	if a := engine.CurrentApp(); p.Shader != "" && a != nil && a.Headless() {
+		m.shaderName = p.Shader
+		return nil
+	}
+
+	if p.Shader != "" {
+		s, err := shader.Get(p.Shader)
+		if err != nil {
+			return err
+		}
+
+		material := engine.NewMaterial()
+		material.SetShader(s)
+		m.SetMaterial(material)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package scene
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/haakenlabs/forge/internal/engine"
+	"github.com/haakenlabs/forge/internal/engine/system/instance"
+)
+
+// SkinnedMeshRenderer renders the skinned meshes of its GameObject, deformed
+// by the joints of a skeleton. The joints are looked up by the joint paths of
+// the mesh, relative to the root object.
+type SkinnedMeshRenderer struct {
+	MeshRenderer
+
+	root     string
+	skeleton *engine.Skeleton
+	bindErr  bool
+}
+
+var _ engine.Renderer = &SkinnedMeshRenderer{}
+
+type skinnedMeshRendererProperties struct {
+	meshRendererProperties
+
+	Root string `json:"root,omitempty"`
+}
+
+func init() {
+	engine.RegisterComponentType("SkinnedMeshRenderer", &SkinnedMeshRenderer{}, decodeSkinnedMeshRenderer)
+	engine.RegisterAnimationPropertyResolver("SkinnedMeshRenderer", morphWeightProperty)
+}
+
+func NewSkinnedMeshRenderer() *SkinnedMeshRenderer {
+	c := &SkinnedMeshRenderer{
+		MeshRenderer: MeshRenderer{
+			cullFace:   true,
+			depthWrite: true,
+		},
+	}
+
+	c.SetName("SkinnedMeshRenderer")
+	instance.MustAssign(c)
+
+	return c
+}
+
+// SkinnedMeshRendererComponent gets the first occurrence of SkinnedMeshRenderer
+// from the entity.
+func SkinnedMeshRendererComponent(g *engine.GameObject) *SkinnedMeshRenderer {
+	return engine.GetComponent[*SkinnedMeshRenderer](g)
+}
+
+// Root returns the path of the skeleton root, relative to the GameObject. An
+// empty path is the GameObject itself.
+func (m *SkinnedMeshRenderer) Root() string {
+	return m.root
+}
+
+// SetRoot sets the path of the skeleton root. The skeleton is bound again on
+// the next render.
+func (m *SkinnedMeshRenderer) SetRoot(root string) {
+	m.root = root
+	m.Rebind()
+}
+
+// Skeleton returns the skeleton of the renderer, or nil if it is not bound.
+func (m *SkinnedMeshRenderer) Skeleton() *engine.Skeleton {
+	return m.skeleton
+}
+
+// SetSkeleton sets the skeleton of the renderer.
+func (m *SkinnedMeshRenderer) SetSkeleton(skeleton *engine.Skeleton) {
+	m.skeleton = skeleton
+	m.bindErr = false
+}
+
+// Rebind discards the skeleton, so it is looked up again on the next render.
+func (m *SkinnedMeshRenderer) Rebind() {
+	m.skeleton = nil
+	m.bindErr = false
+}
+
+func (m *SkinnedMeshRenderer) Render(camera *engine.Camera) {
+	m.render(camera, m.RenderShader)
+}
+
+// RenderShader uploads the joint matrices and draws the meshes. Meshes are
+// drawn without skinning if the skeleton cannot be bound.
+func (m *SkinnedMeshRenderer) RenderShader(shader *engine.Shader, camera *engine.Camera) {
+	if shader == nil || m.GameObject() == nil {
+		return
+	}
+
+	skeleton := m.bind()
+	if skeleton == nil {
+		m.MeshRenderer.RenderShader(shader, camera)
+		return
+	}
+
+	shader.SetUniform("v_skinned", true)
+	shader.SetUniform("v_joint_matrices", skeleton.Matrices(m.GetTransform().RenderMatrix()))
+
+	m.MeshRenderer.RenderShader(shader, camera)
+
+	shader.SetUniform("v_skinned", false)
+}
+
+// bind returns the skeleton, creating it from the first skinned mesh if
+// needed. Errors are logged once until the renderer is bound again.
+func (m *SkinnedMeshRenderer) bind() *engine.Skeleton {
+	if m.skeleton != nil || m.bindErr {
+		return m.skeleton
+	}
+
+	var mesh *engine.Mesh
+	for _, v := range m.meshes() {
+		if v.Skinned() {
+			mesh = v
+			break
+		}
+	}
+	if mesh == nil {
+		return nil
+	}
+
+	root := m.GameObject()
+	if m.root != "" {
+		root = root.Find(m.root)
+	}
+	if root == nil {
+		logrus.Errorf("skinned mesh renderer: root %s not found", m.root)
+		m.bindErr = true
+		return nil
+	}
+
+	skeleton, err := engine.NewSkeletonForMesh(root, mesh)
+	if err != nil {
+		logrus.Errorf("skinned mesh renderer: %s", err)
+		m.bindErr = true
+		return nil
+	}
+
+	m.skeleton = skeleton
+
+	return skeleton
+}
+
+// CloneComponent returns a copy of the SkinnedMeshRenderer. The skeleton is
+// bound again when the copy first renders.
+func (m *SkinnedMeshRenderer) CloneComponent() (engine.Component, error) {
+	n := NewSkinnedMeshRenderer()
+	m.copyTo(&n.MeshRenderer)
+	n.root = m.root
+
+	return n, nil
+}
+
+// EncodeProperties returns the JSON encoded properties of the
+// SkinnedMeshRenderer.
+func (m *SkinnedMeshRenderer) EncodeProperties() ([]byte, error) {
+	return json.Marshal(&skinnedMeshRendererProperties{
+		meshRendererProperties: *m.properties(),
+		Root:                   m.root,
+	})
+}
+
+func decodeSkinnedMeshRenderer(properties []byte) (engine.Component, error) {
+	p := &skinnedMeshRendererProperties{
+		meshRendererProperties: meshRendererProperties{
+			CullFace:   true,
+			DepthWrite: true,
+		},
+	}
+
+	if properties != nil {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return nil, err
+		}
+	}
+
+	m := NewSkinnedMeshRenderer()
+	if err := m.applyProperties(&p.meshRendererProperties); err != nil {
+		return nil, err
+	}
+	m.root = p.Root
+
+	return m, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"sort"
+)
+
+type ErrSubSceneExists string
+type ErrSubSceneNotFound string
+
+func (e ErrSubSceneExists) Error() string {
+	return "sub-scene " + string(e) + " already loaded"
+}
+
+func (e ErrSubSceneNotFound) Error() string {
+	return "sub-scene " + string(e) + " not loaded"
+}
+
+// LoadAdditive adds the objects described by the metadata to this scene as a
+// sub-scene. The objects are placed under a new top level GameObject with the
+// given name, which is returned. Sub-scenes share the scene graph and the
+// environment of the scene, and can be removed again with UnloadAdditive.
+func (s *Scene) LoadAdditive(name string, m *SceneMetadata) (*GameObject, error) {
+	if s.SubScene(name) != nil {
+		return nil, ErrSubSceneExists(name)
+	}
+
+	root := NewGameObject(name)
+
+	for i := range m.Objects {
+		object, err := NewGameObjectFromMetadata(m.Objects[i])
+		if err != nil {
+			destroyGameObjects(root.hierarchy())
+			return nil, err
+		}
+
+		root.AddChild(object)
+		object.SetParent(root)
+		object.OnParentChanged()
+	}
+
+	if err := s.graph.AddGameObject(root, nil); err != nil {
+		return nil, err
+	}
+
+	s.subScenes[name] = root
+
+	return root, nil
+}
+
+// LoadAdditiveFromAsset is like LoadAdditive, using the scene asset with the
+// given name.
+func (s *Scene) LoadAdditiveFromAsset(name, asset string) (*GameObject, error) {
+	h, err := handlerOf[*SceneHandler](s.App().Asset(), AssetNameScene)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := h.Get(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.LoadAdditive(name, f.Metadata())
+}
+
+// UnloadAdditive destroys the objects of the sub-scene with the given name.
+// Like GameObject.Destroy, removal from the scene graph is deferred until the
+// command buffer is flushed.
+func (s *Scene) UnloadAdditive(name string) error {
+	root := s.SubScene(name)
+	if root == nil {
+		return ErrSubSceneNotFound(name)
+	}
+
+	delete(s.subScenes, name)
+	root.Destroy()
+
+	return nil
+}
+
+// SubScene returns the root object of the sub-scene with the given name, or
+// nil if it is not loaded.
+func (s *Scene) SubScene(name string) *GameObject {
+	root, ok := s.subScenes[name]
+	if ok && root.Destroyed() {
+		// The root was destroyed directly rather than unloaded.
+		delete(s.subScenes, name)
+		return nil
+	}
+
+	return root
+}
+
+// SubScenes returns the names of all loaded sub-scenes in sorted order.
+func (s *Scene) SubScenes() []string {
+	names := make([]string, 0, len(s.subScenes))
+	for name := range s.subScenes {
+		if s.SubScene(name) != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
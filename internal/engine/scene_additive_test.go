@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+func TestScene_LoadAdditive(t *testing.T) {
+	setupTestApp(t)
+
+	scene := NewScene("test")
+	scene.Setup()
+	graph := scene.Graph()
+
+	m := &SceneMetadata{
+		Objects: []*GameObjectMetadata{
+			{Name: "a", Active: true},
+			{Name: "b", Active: true},
+		},
+	}
+
+	root, err := scene.LoadAdditive("chunk", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.Update()
+
+	if len(root.Children()) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(root.Children()))
+	}
+	if graph.Find("chunk/a") == nil {
+		t.Fatal("expected chunk/a in scene graph")
+	}
+	if _, err := scene.LoadAdditive("chunk", m); err == nil {
+		t.Fatal("expected error loading chunk twice")
+	}
+	if names := scene.SubScenes(); len(names) != 1 || names[0] != "chunk" {
+		t.Fatalf("unexpected sub-scenes %v", names)
+	}
+
+	if err := scene.UnloadAdditive("chunk"); err != nil {
+		t.Fatal(err)
+	}
+	graph.FlushCommands()
+
+	if scene.SubScene("chunk") != nil {
+		t.Fatal("expected chunk to be unloaded")
+	}
+	if graph.Find("chunk/a") != nil {
+		t.Fatal("expected chunk/a to be removed")
+	}
+	if err := scene.UnloadAdditive("chunk"); err == nil {
+		t.Fatal("expected error unloading missing chunk")
+	}
+}
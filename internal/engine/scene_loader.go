@@ -0,0 +1,341 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultMainThreadBudget = 4
+
+	ErrSceneSwitchInProgress = Error("a scene switch is already in progress")
+)
+
+// SceneLoadOperation tracks the progress of an asynchronous scene load.
+type SceneLoadOperation struct {
+	scene    *Scene
+	mu       sync.Mutex
+	progress float64
+	err      error
+	done     chan struct{}
+}
+
+// Scene returns the scene being loaded.
+func (o *SceneLoadOperation) Scene() *Scene {
+	return o.scene
+}
+
+// Progress returns the load progress in the range [0, 1].
+func (o *SceneLoadOperation) Progress() float64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.progress
+}
+
+// Done reports if the load has finished, successfully or not.
+func (o *SceneLoadOperation) Done() bool {
+	select {
+	case <-o.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Err returns the error of a finished load, if any.
+func (o *SceneLoadOperation) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.err
+}
+
+// Wait blocks until the load has finished and returns its error. It must not
+// be called from the main thread, which has to run the final load steps.
+func (o *SceneLoadOperation) Wait() error {
+	<-o.done
+
+	return o.Err()
+}
+
+func (o *SceneLoadOperation) setProgress(progress float64) {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	o.mu.Lock()
+	o.progress = progress
+	o.mu.Unlock()
+}
+
+func (o *SceneLoadOperation) finish(err error) {
+	o.mu.Lock()
+	o.err = err
+	if err == nil {
+		o.progress = 1
+	}
+	o.mu.Unlock()
+
+	close(o.done)
+}
+
+func newSceneLoadOperation(scene *Scene) *SceneLoadOperation {
+	return &SceneLoadOperation{
+		scene: scene,
+		done:  make(chan struct{}),
+	}
+}
+
+// LoadContext is passed to scene prepare functions. When a scene is loaded
+// asynchronously, the prepare function runs on a worker goroutine and must use
+// Main for anything touching OpenGL or the scene graph.
+type LoadContext struct {
+	app *App
+	op  *SceneLoadOperation
+}
+
+// SetProgress reports the progress of the load in the range [0, 1].
+func (c *LoadContext) SetProgress(progress float64) {
+	if c.op != nil {
+		c.op.setProgress(progress)
+	}
+}
+
+// Main runs fn on the main thread and waits for it to return. When loading
+// synchronously, the caller is the main thread and fn is called directly.
+func (c *LoadContext) Main(fn func() error) error {
+	if c.app == nil || c.op == nil {
+		return fn()
+	}
+
+	result := make(chan error, 1)
+	c.app.RunOnMain(func() {
+		result <- fn()
+	})
+
+	return <-result
+}
+
+// LoadAsset reads the asset file on the calling goroutine and loads it with
+// the handler for the given kind. Handlers implementing AssetDecoder decode the
+// file on the calling goroutine and only upload it on the main thread, other
+// handlers load it entirely on the main thread.
+func (c *LoadContext) LoadAsset(kind, filename string) error {
+	asset := c.app.Asset()
+
+	h, err := asset.GetHandler(kind)
+	if err != nil {
+		return err
+	}
+
+	r, err := NewResource(filename)
+	if err != nil {
+		return err
+	}
+	if err := asset.ReadResource(r); err != nil {
+		return err
+	}
+
+	if d, ok := h.(AssetDecoder); ok {
+		upload, err := d.Decode(r)
+		if err != nil {
+			return err
+		}
+
+		return c.Main(upload)
+	}
+
+	return c.Main(func() error {
+		return h.Load(r)
+	})
+}
+
+// RunOnMain schedules fn to be run on the main thread at the start of a
+// frame. At most MainThreadBudget functions are run per frame. It is safe to
+// call from any goroutine.
+func (a *App) RunOnMain(fn func()) {
+	a.mainMu.Lock()
+	a.mainTasks = append(a.mainTasks, fn)
+	a.mainMu.Unlock()
+}
+
+func (a *App) runMainTasks() {
+	a.mainMu.Lock()
+	n := len(a.mainTasks)
+	if n > a.mainBudget {
+		n = a.mainBudget
+	}
+	tasks := make([]func(), n)
+	copy(tasks, a.mainTasks)
+	a.mainTasks = a.mainTasks[n:]
+	a.mainMu.Unlock()
+
+	for i := range tasks {
+		tasks[i]()
+	}
+}
+
+// LoadSceneAsync starts loading the scene on a worker goroutine. The prepare
+// function of the scene runs on the worker, the load function runs on the
+// main thread once preparation is complete. Loading a scene which is already
+// loading returns the pending operation.
+func (a *App) LoadSceneAsync(name string) (*SceneLoadOperation, error) {
+	if !a.SceneRegistered(name) {
+		return nil, fmt.Errorf("load scene async: '%s' not registered", name)
+	}
+
+	s := a.scenes[name]
+
+	if s.loadOp != nil {
+		return s.loadOp, nil
+	}
+
+	op := newSceneLoadOperation(s)
+
+	if s.Loaded() {
+		op.finish(nil)
+		return op, nil
+	}
+
+	s.loadOp = op
+
+	go func() {
+		ctx := &LoadContext{app: a, op: op}
+
+		if s.prepareFunc != nil {
+			if err := s.prepareFunc(ctx); err != nil {
+				a.RunOnMain(func() {
+					s.loadOp = nil
+					op.finish(err)
+				})
+				return
+			}
+		}
+
+		a.RunOnMain(func() {
+			s.loadOp = nil
+			op.finish(s.finishLoad())
+		})
+	}()
+
+	return op, nil
+}
+
+// sceneSwitch is an in progress SwitchScene.
+type sceneSwitch struct {
+	name       string
+	op         *SceneLoadOperation
+	transition Transition
+	depth      int
+	switched   bool
+}
+
+// SwitchScene loads the scene asynchronously and replaces the active scene
+// with it once loaded. The transition, which may be nil, is shown while the
+// scene loads. Scenes pushed after the switch began, such as a loading scene,
+// are removed as well.
+func (a *App) SwitchScene(name string, transition Transition) (*SceneLoadOperation, error) {
+	if a.sceneSwitch != nil {
+		return nil, ErrSceneSwitchInProgress
+	}
+
+	op, err := a.LoadSceneAsync(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a.sceneSwitch = &sceneSwitch{
+		name:       name,
+		op:         op,
+		transition: transition,
+		depth:      len(a.activeScenes),
+	}
+
+	if transition != nil {
+		transition.Begin(a, op)
+	}
+
+	return op, nil
+}
+
+// Loading returns the load operation of the scene switch in progress, or nil.
+func (a *App) Loading() *SceneLoadOperation {
+	if a.sceneSwitch != nil {
+		return a.sceneSwitch.op
+	}
+
+	return nil
+}
+
+func (a *App) updateSceneSwitch(delta float64) {
+	w := a.sceneSwitch
+	if w == nil {
+		return
+	}
+
+	t := w.transition
+
+	if !w.switched {
+		hidden := t == nil || t.Out(delta)
+		if !hidden || !w.op.Done() {
+			return
+		}
+
+		if err := w.op.Err(); err != nil {
+			// Remove scenes pushed by the transition, such as a loading
+			// scene, and keep the scene the switch started from.
+			for len(a.activeScenes) > w.depth {
+				a.PopScene()
+			}
+			logrus.Error("switch scene: ", err)
+		} else {
+			for len(a.activeScenes) > 0 && len(a.activeScenes) >= w.depth {
+				a.PopScene()
+			}
+			if err := a.PushScene(w.name); err != nil {
+				logrus.Error("switch scene: ", err)
+			}
+		}
+
+		w.switched = true
+
+		if t != nil {
+			t.Switched(a)
+		}
+	}
+
+	if t == nil || t.In(delta) {
+		if t != nil {
+			t.End(a)
+		}
+
+		a.sceneSwitch = nil
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitMain runs main thread tasks and scene switches until done returns true.
+func waitMain(t *testing.T, a *App, done func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for main thread work")
+		}
+
+		a.runMainTasks()
+		a.updateSceneSwitch(0)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newAsyncTestScene(t *testing.T, a *App, name string) *Scene {
+	s := NewScene(name)
+	s.SetPrepareFunc(func(ctx *LoadContext) error {
+		ctx.SetProgress(0.5)
+
+		return ctx.Main(func() error {
+			return nil
+		})
+	})
+	s.SetLoadFunc(func() error {
+		return s.Graph().AddGameObject(NewGameObject("object"), nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestApp_RunOnMainBudget(t *testing.T) {
+	a := setupTestApp(t)
+
+	count := 0
+	for i := 0; i < a.mainBudget+2; i++ {
+		a.RunOnMain(func() {
+			count++
+		})
+	}
+
+	a.runMainTasks()
+
+	if count != a.mainBudget {
+		t.Errorf("tasks run: %d, expected %d", count, a.mainBudget)
+	}
+
+	a.runMainTasks()
+
+	if count != a.mainBudget+2 {
+		t.Errorf("tasks run: %d, expected %d", count, a.mainBudget+2)
+	}
+}
+
+func TestApp_LoadSceneAsync(t *testing.T) {
+	a := setupTestApp(t)
+	s := newAsyncTestScene(t, a, "async")
+
+	op, err := a.LoadSceneAsync("async")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitMain(t, a, op.Done)
+
+	if op.Err() != nil {
+		t.Fatal(op.Err())
+	}
+	if op.Progress() != 1 {
+		t.Errorf("progress: %v, expected 1", op.Progress())
+	}
+	if !s.Loaded() || s.Graph().Find("/object") == nil {
+		t.Error("scene not loaded")
+	}
+}
+
+func TestApp_SwitchScene(t *testing.T) {
+	a := setupTestApp(t)
+
+	if err := a.RegisterScene(NewScene("switch-from")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RegisterScene(NewScene("switch-loading")); err != nil {
+		t.Fatal(err)
+	}
+	newAsyncTestScene(t, a, "switch-to")
+
+	if err := a.PushScene("switch-from"); err != nil {
+		t.Fatal(err)
+	}
+	depth := a.ActiveSceneCount()
+
+	if _, err := a.SwitchScene("switch-to", NewLoadingSceneTransition("switch-loading")); err != nil {
+		t.Fatal(err)
+	}
+	defer a.PopScene()
+
+	if a.ActiveSceneName() != "switch-loading" {
+		t.Errorf("active scene: %s, expected switch-loading", a.ActiveSceneName())
+	}
+	if _, err := a.SwitchScene("switch-to", nil); err != ErrSceneSwitchInProgress {
+		t.Errorf("second switch: %v, expected %v", err, ErrSceneSwitchInProgress)
+	}
+
+	waitMain(t, a, func() bool {
+		return a.Loading() == nil
+	})
+
+	if a.ActiveSceneName() != "switch-to" {
+		t.Errorf("active scene: %s, expected switch-to", a.ActiveSceneName())
+	}
+	if a.ActiveSceneCount() != depth {
+		t.Errorf("active scenes: %d, expected %d", a.ActiveSceneCount(), depth)
+	}
+}
+
+func TestApp_SwitchSceneError(t *testing.T) {
+	a := setupTestApp(t)
+
+	if err := a.RegisterScene(NewScene("error-from")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RegisterScene(NewScene("error-loading")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScene("error-to")
+	s.SetPrepareFunc(func(*LoadContext) error {
+		return Error("prepare failed")
+	})
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.PushScene("error-from"); err != nil {
+		t.Fatal(err)
+	}
+	defer a.PopScene()
+	depth := a.ActiveSceneCount()
+
+	if _, err := a.SwitchScene("error-to", NewLoadingSceneTransition("error-loading")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitMain(t, a, func() bool {
+		return a.Loading() == nil
+	})
+
+	if a.ActiveSceneName() != "error-from" || a.ActiveSceneCount() != depth {
+		t.Errorf("active scene: %s of %d, expected error-from of %d", a.ActiveSceneName(), a.ActiveSceneCount(), depth)
+	}
+}
+
+func TestScene_LoadMain(t *testing.T) {
+	a := setupTestApp(t)
+
+	ran := false
+	s := NewScene("sync")
+	s.SetPrepareFunc(func(ctx *LoadContext) error {
+		return ctx.Main(func() error {
+			ran = true
+			return nil
+		})
+	})
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// A synchronous load runs on the main thread, so Main must not wait for
+	// main thread tasks.
+	result := make(chan error, 1)
+	go func() {
+		result <- a.PushScene("sync")
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("synchronous load blocked in LoadContext.Main")
+	}
+	defer a.PopScene()
+
+	if !ran || !s.Loaded() {
+		t.Error("scene not loaded")
+	}
+}
+
+// testDecoderHandler records the steps of loading an asset through
+// AssetDecoder.
+type testDecoderHandler struct {
+	BaseAssetHandler
+
+	decoded chan string
+	uploads int
+}
+
+func (h *testDecoderHandler) Load(r *Resource) error {
+	upload, err := h.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	return upload()
+}
+
+func (h *testDecoderHandler) Decode(r *Resource) (func() error, error) {
+	h.decoded <- string(r.Bytes())
+
+	return func() error {
+		h.uploads++
+		return nil
+	}, nil
+}
+
+func (h *testDecoderHandler) Name() string {
+	return "test-decoder"
+}
+
+func TestLoadContext_LoadAsset(t *testing.T) {
+	a := setupTestApp(t)
+
+	h := &testDecoderHandler{decoded: make(chan string, 1)}
+	if err := a.Asset().RegisterHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "asset.txt")
+	if err := os.WriteFile(filename, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &LoadContext{app: a, op: newSceneLoadOperation(nil)}
+	result := make(chan error, 1)
+	go func() {
+		result <- ctx.LoadAsset("test-decoder", filename)
+	}()
+
+	// The asset is decoded without the main thread running, and uploaded
+	// only once it does.
+	select {
+	case data := <-h.decoded:
+		if data != "data" {
+			t.Errorf("decoded %q, expected data", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("asset not decoded on the loading goroutine")
+	}
+
+	if h.uploads != 0 {
+		t.Error("asset uploaded before main thread tasks ran")
+	}
+
+	var err error
+	waitMain(t, a, func() bool {
+		select {
+		case err = <-result:
+			return true
+		default:
+			return false
+		}
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.uploads != 1 {
+		t.Errorf("uploads: %d, expected 1", h.uploads)
+	}
+}
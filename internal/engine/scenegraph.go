@@ -23,6 +23,8 @@ SOFTWARE.
 package engine
 
 import (
+	"reflect"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/haakenlabs/forge/internal/sg"
@@ -33,12 +35,22 @@ type SceneGraphListener interface {
 	OnSceneGraphUpdate()
 }
 
+const (
+	ErrRemoveRootNode  = Error("cannot remove scene graph root node")
+	ErrMoveRootNode    = Error("cannot move scene graph root node")
+	ErrParentNotInHere = Error("parent is not part of this scene graph")
+)
+
 type SceneGraph struct {
 	root           *GameObject
 	graph          *sg.Graph
 	active         []*GameObject
 	componentCache []Component
+	commands       *CommandBuffer
+	tagIndex       map[string][]*GameObject
+	typeIndex      map[reflect.Type][]Component
 	scene          *Scene
+	dispatching    int
 	dirty          bool
 }
 
@@ -49,14 +61,26 @@ func NewSceneGraph(scene *Scene) *SceneGraph {
 		dirty:          true,
 		active:         []*GameObject{},
 		componentCache: []Component{},
+		tagIndex:       make(map[string][]*GameObject),
+		typeIndex:      make(map[reflect.Type][]Component),
 	}
 
 	s.root = NewGameObject("__rootNode__")
+	s.commands = NewCommandBuffer(s)
 
 	return s
 }
 
+// Update rebuilds the active object and component caches. While messages are
+// being dispatched the update is deferred by marking the graph dirty.
 func (s *SceneGraph) Update() {
+	if s.dispatching > 0 {
+		s.dirty = true
+		return
+	}
+
+	defer s.App().Profiler().Begin("scenegraph_update").End()
+
 	dfs := s.graph.DepthFirstSearch(1, false)
 	s.active = s.active[:0]
 	s.componentCache = s.componentCache[:0]
@@ -67,12 +91,25 @@ func (s *SceneGraph) Update() {
 		s.componentCache = append(s.componentCache, o.Components()...)
 	}
 
+	s.rebuildIndexes()
+
 	s.dirty = false
 	s.notifyListeners()
 
 	logrus.Debugf("SceneGraph updated. activeObjects: %d componentCache: %d", len(s.active), len(s.componentCache))
 }
 
+// Root returns the root node of the graph. Top level objects are children of
+// the root node.
+func (s *SceneGraph) Root() *GameObject {
+	return s.root
+}
+
+// App returns the App of the scene the graph belongs to.
+func (s *SceneGraph) App() *App {
+	return s.scene.App()
+}
+
 // Dirty returns the state of the graph. If true, the graph needs an update.
 func (s *SceneGraph) Dirty() bool {
 	return s.dirty
@@ -83,7 +120,25 @@ func (s *SceneGraph) SetDirty() {
 	s.dirty = true
 }
 
+// Commands returns the command buffer of the graph.
+func (s *SceneGraph) Commands() *CommandBuffer {
+	return s.commands
+}
+
+// AddGameObject adds the object and its descendants to the graph under the
+// given parent. A nil parent adds the object to the top level of the graph.
+// If called while messages are being dispatched, the object is spawned when
+// the command buffer is next flushed.
 func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
+	if s.dispatching > 0 {
+		s.commands.Spawn(object, parent)
+		return nil
+	}
+
+	return s.addGameObject(object, parent)
+}
+
+func (s *SceneGraph) addGameObject(object, parent *GameObject) error {
 	var err error
 	var u sg.VertexDescriptor // parent
 	var v sg.VertexDescriptor // object
@@ -111,7 +166,7 @@ func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
 	// Add children, if any.
 	children := object.Children()
 	for i := range children {
-		if err := s.AddGameObject(children[i], object); err != nil {
+		if err := s.addGameObject(children[i], object); err != nil {
 			return err
 		}
 	}
@@ -127,12 +182,131 @@ func (s *SceneGraph) AddGameObject(object, parent *GameObject) error {
 	return nil
 }
 
+// SetParent moves the object and its descendants under a new parent. A nil
+// parent moves the object to the top level of the graph. If keepWorld is true,
+// the local transform of the object is recomputed so that its world transform
+// does not change. If called while messages are being dispatched, the move is
+// deferred until the command buffer is next flushed.
+func (s *SceneGraph) SetParent(object, parent *GameObject, keepWorld bool) error {
+	if object == s.root {
+		return ErrMoveRootNode
+	}
+	if s.dispatching > 0 {
+		s.commands.SetParent(object, parent, keepWorld)
+		return nil
+	}
+	if parent == nil {
+		parent = s.root
+	}
+	if parent != s.root && parent.Scene() != s.scene {
+		return ErrParentNotInHere
+	}
+
+	u, err := s.graph.GetVertexById(object.ID())
+	if err != nil {
+		return err
+	}
+	v, err := s.graph.GetVertexByObject(parent)
+	if err != nil {
+		return err
+	}
+
+	world := object.Transform().ActiveMatrix()
+
+	if err := s.graph.MoveVertex(u, v); err != nil {
+		return err
+	}
+
+	if old := object.Parent(); old != nil {
+		old.removeChild(object)
+	}
+	parent.AddChild(object)
+	object.SetParent(parent)
+
+	if keepWorld {
+		local := parent.Transform().ActiveMatrix().Inv().Mul4(world)
+		position, rotation, scale := decomposeMatrix(local)
+
+		t := object.Transform()
+		t.SetScale(scale)
+		t.SetRotation(rotation)
+		t.SetPosition(position)
+	}
+
+	object.OnParentChanged()
+	object.refreshActive()
+
+	s.SetDirty()
+
+	return nil
+}
+
+// RemoveGameObject removes the object and its descendants from the graph and
+// destroys them. If called while messages are being dispatched, removal is
+// deferred until the command buffer is next flushed.
+func (s *SceneGraph) RemoveGameObject(object *GameObject) error {
+	if object == s.root {
+		return ErrRemoveRootNode
+	}
+	if s.dispatching > 0 {
+		s.commands.Destroy(object)
+		return nil
+	}
+
+	return s.removeGameObject(object)
+}
+
+func (s *SceneGraph) removeGameObject(object *GameObject) error {
+	if object == s.root {
+		return ErrRemoveRootNode
+	}
+
+	objects, err := s.remove(object)
+	if err != nil {
+		return err
+	}
+
+	destroyGameObjects(objects)
+
+	s.Update()
+
+	return nil
+}
+
+// FlushCommands applies all structural changes queued in the command buffer.
+func (s *SceneGraph) FlushCommands() {
+	s.commands.Flush()
+}
+
 func (s *SceneGraph) SendMessage(message Message) {
+	s.dispatching++
+	defer func() { s.dispatching-- }()
+
 	for i := range s.active {
 		s.active[i].SendMessage(message)
 	}
 }
 
+// snapshotTransforms stores the state of interpolated transforms before a
+// fixed update tick.
+func (s *SceneGraph) snapshotTransforms() {
+	for i := range s.active {
+		if t, ok := s.active[i].Transform().(interpolatedTransform); ok {
+			t.snapshot()
+		}
+	}
+}
+
+// interpolateTransforms updates the render matrices of all active transforms.
+// Objects are visited parents first so children follow interpolated parents.
+func (s *SceneGraph) interpolateTransforms(alpha float32) {
+	for i := range s.active {
+		if t, ok := s.active[i].Transform().(interpolatedTransform); ok {
+			t.updateRenderMatrix(alpha)
+		}
+	}
+}
+
 func (s *SceneGraph) Parent(e *GameObject) *GameObject {
 	v, err := s.graph.GetVertexByObject(e)
 	if err != nil {
@@ -191,6 +365,54 @@ func (s *SceneGraph) Components() []Component {
 	return s.componentCache
 }
 
+// remove removes the object and its descendants from the graph and from the
+// children of its parent. The removed objects are returned, parents first.
+func (s *SceneGraph) remove(object *GameObject) ([]*GameObject, error) {
+	u, err := s.graph.GetVertexById(object.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []*GameObject{}
+	for _, v := range s.graph.DepthFirstSearch(u, true) {
+		objects = append(objects, s.objectAt(v))
+	}
+
+	if parent := object.Parent(); parent != nil {
+		parent.removeChild(object)
+	}
+
+	if err := s.graph.RemoveVertex(u); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// detach removes the object and its descendants from the graph without
+// destroying them.
+func (s *SceneGraph) detach(object *GameObject) {
+	hierarchy := object.hierarchy()
+	for i := len(hierarchy) - 1; i >= 0; i-- {
+		if hierarchy[i].live {
+			hierarchy[i].setLive(false)
+		}
+		hierarchy[i].awake = false
+	}
+
+	objects, err := s.remove(object)
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	for i := range objects {
+		objects[i].scene = nil
+	}
+
+	s.Update()
+}
+
 func (s *SceneGraph) objectAt(u sg.VertexDescriptor) *GameObject {
 	obj := s.graph.GetObjectAtVertex(u)
 	if obj == nil {
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// matrixNear reports if all elements of the matrices differ by less than 1e-4.
+func matrixNear(a, b mgl32.Mat4) bool {
+	for i := range a {
+		if d := a[i] - b[i]; d > 1e-4 || d < -1e-4 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSceneGraph_SetParentKeepWorld(t *testing.T) {
+	s := newTestScene(t)
+
+	a := NewGameObject("a")
+	b := NewGameObject("b")
+	b.Transform().SetPosition(mgl32.Vec3{1, 2, 3})
+	b.Transform().SetRotation(mgl32.QuatRotate(0.7, mgl32.Vec3{0, 0, 1}))
+	b.Transform().SetScale(mgl32.Vec3{2, 2, 2})
+
+	object := NewGameObject("object")
+	object.Transform().SetPosition(mgl32.Vec3{5, 0, 0})
+	object.Transform().SetRotation(mgl32.QuatRotate(0.3, mgl32.Vec3{1, 0, 0}))
+	script := newTestScript(nil)
+	object.AddComponent(script)
+
+	mustAdd(t, s, a, nil)
+	mustAdd(t, s, b, nil)
+	mustAdd(t, s, object, a)
+
+	world := object.Transform().ActiveMatrix()
+
+	if err := s.SetParent(object, b, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := object.Transform().ActiveMatrix(); !matrixNear(m, world) {
+		t.Errorf("world matrix changed:\n%v\nexpected\n%v", m, world)
+	}
+
+	if object.Parent() != b || len(a.Children()) != 0 || len(b.Children()) != 1 {
+		t.Error("children not updated")
+	}
+	if s.Find("/a/object") != nil || s.Find("/b/object") != object {
+		t.Error("graph paths not updated")
+	}
+	if ancestors := object.Ancestors(); len(ancestors) == 0 || ancestors[0] != b {
+		t.Errorf("ancestors %v, expected b first", ancestors)
+	}
+	if len(s.Descendants(a)) != 0 || len(s.Descendants(b)) != 1 {
+		t.Error("descendants not updated")
+	}
+	if len(a.ComponentsInChildren()) != 0 || len(b.ComponentsInChildren()) != 2 {
+		t.Error("components in children not updated")
+	}
+}
+
+func TestSceneGraph_SetParent(t *testing.T) {
+	s := newTestScene(t)
+
+	parent := NewGameObject("parent")
+	parent.Transform().SetPosition(mgl32.Vec3{1, 2, 3})
+	parent.Transform().SetScale(mgl32.Vec3{2, 2, 2})
+
+	object := NewGameObject("object")
+	object.Transform().SetPosition(mgl32.Vec3{5, 0, 0})
+
+	mustAdd(t, s, parent, nil)
+	mustAdd(t, s, object, nil)
+
+	if err := s.SetParent(object, parent, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without keepWorld the local transform is kept and the object moves with
+	// its new parent.
+	if object.Transform().Position() != (mgl32.Vec3{5, 0, 0}) {
+		t.Errorf("local position %v changed", object.Transform().Position())
+	}
+	expected := parent.Transform().ActiveMatrix().Mul4(mgl32.Translate3D(5, 0, 0))
+	if m := object.Transform().ActiveMatrix(); !matrixNear(m, expected) {
+		t.Errorf("world matrix %v, expected %v", m, expected)
+	}
+
+	if err := s.SetParent(object, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if object.Parent() != nil && object.Parent() != s.root {
+		t.Errorf("parent %v, expected root", object.Parent())
+	}
+	if s.Find("/object") != object || len(parent.Children()) != 0 {
+		t.Error("object not moved to the root")
+	}
+}
@@ -0,0 +1,441 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"runtime"
+)
+
+type waitKind int
+
+const (
+	waitNone waitKind = iota
+	waitFrame
+	waitFixedUpdate
+	waitSeconds
+	waitUntil
+)
+
+// Task is a delayed call, repeating timer or coroutine run by a Scheduler.
+type Task struct {
+	scheduler *Scheduler
+	owner     Component
+	fn        func()
+	tick      func(float64) bool
+	co        *Coroutine
+	remaining float64
+	interval  float64
+	repeat    bool
+	done      bool
+}
+
+// Coroutine is the handle a coroutine function uses to yield. Its methods
+// suspend the coroutine and must only be called from the coroutine itself.
+//
+// Coroutines run on their own goroutine, but never concurrently with the main
+// thread: the scheduler waits while a coroutine runs until it yields.
+type Coroutine struct {
+	task      *Task
+	resume    chan bool
+	yield     chan struct{}
+	wait      waitKind
+	remaining float64
+	until     func() bool
+	panicked  interface{}
+	started   bool
+	running   bool
+	finished  bool
+}
+
+// Scheduler runs delayed calls, repeating timers and coroutines of a scene.
+// Tasks advance with the scaled delta time of the App while the scene is
+// updated, starting with the frame after they were added. They are paused
+// while the scheduler or game time is paused, or the owner is inactive, and
+// cancelled when the owner is destroyed or the scene is torn down.
+type Scheduler struct {
+	tasks   []*Task
+	pending []*Task
+	paused  bool
+}
+
+// Cancel stops the task. Cancelling a coroutine unwinds it, running its
+// deferred functions.
+func (t *Task) Cancel() {
+	if t.done {
+		return
+	}
+
+	t.done = true
+
+	if t.co != nil {
+		t.co.cancel()
+	}
+}
+
+// Done reports if the task has finished or was cancelled.
+func (t *Task) Done() bool {
+	return t.done
+}
+
+// Owner returns the component owning the task, if any.
+func (t *Task) Owner() Component {
+	return t.owner
+}
+
+// WaitFrame suspends the coroutine until the next frame.
+func (co *Coroutine) WaitFrame() {
+	co.suspend(waitFrame)
+}
+
+// WaitFixedUpdate suspends the coroutine until the next fixed update tick.
+func (co *Coroutine) WaitFixedUpdate() {
+	co.suspend(waitFixedUpdate)
+}
+
+// WaitSeconds suspends the coroutine for the given amount of scaled time.
+func (co *Coroutine) WaitSeconds(seconds float64) {
+	co.remaining = seconds
+	co.suspend(waitSeconds)
+}
+
+// WaitUntil suspends the coroutine until cond returns true. The condition is
+// checked once per frame.
+func (co *Coroutine) WaitUntil(cond func() bool) {
+	co.until = cond
+	co.suspend(waitUntil)
+}
+
+func (co *Coroutine) suspend(wait waitKind) {
+	// The task was cancelled by the coroutine itself.
+	if co.task.done {
+		runtime.Goexit()
+	}
+
+	co.wait = wait
+	co.yield <- struct{}{}
+
+	if !<-co.resume {
+		runtime.Goexit()
+	}
+}
+
+// step runs the coroutine until it yields or returns.
+func (co *Coroutine) step(fn func(*Coroutine)) {
+	if !co.started {
+		co.started = true
+
+		go func() {
+			defer func() {
+				co.panicked = recover()
+				co.finished = true
+				close(co.yield)
+			}()
+
+			if <-co.resume {
+				fn(co)
+			}
+		}()
+	}
+
+	co.running = true
+	co.resume <- true
+	<-co.yield
+	co.running = false
+
+	if co.panicked != nil {
+		panic(co.panicked)
+	}
+	if co.finished {
+		co.task.done = true
+	}
+}
+
+func (co *Coroutine) cancel() {
+	// A running coroutine exits when it next yields.
+	if !co.started || co.running || co.finished {
+		return
+	}
+
+	co.resume <- false
+	<-co.yield
+}
+
+// After calls fn once after the given delay in seconds.
+func (s *Scheduler) After(owner Component, delay float64, fn func()) *Task {
+	return s.add(&Task{owner: owner, fn: fn, remaining: delay})
+}
+
+// Every calls fn repeatedly, every interval seconds, until the task is
+// cancelled. It is called at most once per frame.
+func (s *Scheduler) Every(owner Component, interval float64, fn func()) *Task {
+	return s.add(&Task{owner: owner, fn: fn, remaining: interval, interval: interval, repeat: true})
+}
+
+// Run calls fn every frame with the delta time until it returns true.
+func (s *Scheduler) Run(owner Component, fn func(delta float64) bool) *Task {
+	return s.add(&Task{owner: owner, tick: fn})
+}
+
+// Start starts fn as a coroutine. It runs immediately until it first yields
+// and is resumed by the scheduler as its wait conditions are met.
+func (s *Scheduler) Start(owner Component, fn func(*Coroutine)) *Task {
+	t := &Task{owner: owner}
+	t.co = &Coroutine{
+		task:   t,
+		resume: make(chan bool),
+		yield:  make(chan struct{}),
+	}
+	t.fn = func() {
+		t.co.step(fn)
+	}
+
+	s.add(t)
+	t.fn()
+
+	return t
+}
+
+// Pause pauses all tasks of the scheduler.
+func (s *Scheduler) Pause() {
+	s.paused = true
+}
+
+// Resume resumes the tasks of the scheduler.
+func (s *Scheduler) Resume() {
+	s.paused = false
+}
+
+// Paused reports if the scheduler is paused.
+func (s *Scheduler) Paused() bool {
+	return s.paused
+}
+
+// Len returns the number of pending tasks.
+func (s *Scheduler) Len() int {
+	n := 0
+	for i := range s.tasks {
+		if !s.tasks[i].done {
+			n++
+		}
+	}
+	for i := range s.pending {
+		if !s.pending[i].done {
+			n++
+		}
+	}
+
+	return n
+}
+
+// CancelAll cancels all tasks.
+func (s *Scheduler) CancelAll() {
+	tasks := append(s.tasks, s.pending...)
+	s.tasks = nil
+	s.pending = nil
+
+	for i := range tasks {
+		tasks[i].Cancel()
+	}
+}
+
+func (s *Scheduler) add(t *Task) *Task {
+	t.scheduler = s
+	s.pending = append(s.pending, t)
+
+	return t
+}
+
+// beginFrame schedules the tasks added during the previous frame. It is called
+// before the scene is updated, so tasks added during this frame wait for the
+// next one.
+func (s *Scheduler) beginFrame() {
+	s.tasks = append(s.tasks, s.pending...)
+
+	for i := range s.pending {
+		s.pending[i] = nil
+	}
+	s.pending = s.pending[:0]
+}
+
+// update advances the tasks by the frame delta time.
+func (s *Scheduler) update(delta float64) {
+	if s.paused {
+		return
+	}
+
+	for i := range s.tasks {
+		t := s.tasks[i]
+		if !s.ready(t) {
+			continue
+		}
+
+		if t.co != nil {
+			s.updateCoroutine(t, delta)
+			continue
+		}
+		if t.tick != nil {
+			if t.tick(delta) {
+				t.done = true
+			}
+			continue
+		}
+
+		t.remaining -= delta
+		if t.remaining > 0 {
+			continue
+		}
+
+		if t.repeat {
+			t.remaining += t.interval
+			if t.remaining <= 0 {
+				t.remaining = t.interval
+			}
+		} else {
+			t.done = true
+		}
+
+		t.fn()
+	}
+
+	s.compact()
+}
+
+func (s *Scheduler) updateCoroutine(t *Task, delta float64) {
+	co := t.co
+
+	switch co.wait {
+	case waitFrame:
+	case waitSeconds:
+		co.remaining -= delta
+		if co.remaining > 0 {
+			return
+		}
+	case waitUntil:
+		if !co.until() {
+			return
+		}
+	default:
+		return
+	}
+
+	t.fn()
+}
+
+// fixedUpdate resumes coroutines waiting for a fixed update tick.
+func (s *Scheduler) fixedUpdate() {
+	if s.paused {
+		return
+	}
+
+	for i := range s.tasks {
+		t := s.tasks[i]
+		if s.ready(t) && t.co != nil && t.co.wait == waitFixedUpdate {
+			t.fn()
+		}
+	}
+
+	s.compact()
+}
+
+// ready reports if the task may run, cancelling it if its owner is destroyed.
+func (s *Scheduler) ready(t *Task) bool {
+	if t.done {
+		return false
+	}
+	if ownerExpired(t.owner) {
+		t.Cancel()
+		return false
+	}
+
+	return !ownerInactive(t.owner)
+}
+
+// removeExpired cancels the tasks of destroyed owners.
+func (s *Scheduler) removeExpired() {
+	for _, tasks := range [][]*Task{s.tasks, s.pending} {
+		for i := range tasks {
+			if !tasks[i].done && ownerExpired(tasks[i].owner) {
+				tasks[i].Cancel()
+			}
+		}
+	}
+
+	s.compact()
+}
+
+func (s *Scheduler) compact() {
+	tasks := s.tasks[:0]
+	for i := range s.tasks {
+		if !s.tasks[i].done {
+			tasks = append(tasks, s.tasks[i])
+		}
+	}
+
+	for i := len(tasks); i < len(s.tasks); i++ {
+		s.tasks[i] = nil
+	}
+	s.tasks = tasks
+}
+
+// Scheduler returns the task scheduler of the scene.
+func (s *Scene) Scheduler() *Scheduler {
+	return s.scheduler
+}
+
+// NewScheduler creates a new scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// After calls fn once after the given delay, on the scheduler of the scene
+// owning the component's GameObject.
+func After(owner Component, delay float64, fn func()) (*Task, error) {
+	g := owner.GameObject()
+	if g == nil || g.Scene() == nil {
+		return nil, ErrNotInScene
+	}
+
+	return g.Scene().Scheduler().After(owner, delay, fn), nil
+}
+
+// Every calls fn every interval seconds, on the scheduler of the scene owning
+// the component's GameObject.
+func Every(owner Component, interval float64, fn func()) (*Task, error) {
+	g := owner.GameObject()
+	if g == nil || g.Scene() == nil {
+		return nil, ErrNotInScene
+	}
+
+	return g.Scene().Scheduler().Every(owner, interval, fn), nil
+}
+
+// StartCoroutine starts fn as a coroutine on the scheduler of the scene owning
+// the component's GameObject.
+func StartCoroutine(owner Component, fn func(*Coroutine)) (*Task, error) {
+	g := owner.GameObject()
+	if g == nil || g.Scene() == nil {
+		return nil, ErrNotInScene
+	}
+
+	return g.Scene().Scheduler().Start(owner, fn), nil
+}
@@ -0,0 +1,275 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func stepScheduler(s *Scheduler, delta float64) {
+	s.beginFrame()
+	s.update(delta)
+}
+
+func TestScheduler_After(t *testing.T) {
+	s := NewScheduler()
+
+	calls := 0
+	task := s.After(nil, 1, func() { calls++ })
+
+	stepScheduler(s, 0.5)
+	if calls != 0 {
+		t.Fatal("called before delay")
+	}
+	stepScheduler(s, 0.5)
+	if calls != 1 || !task.Done() {
+		t.Fatalf("calls: %d, done: %v, expected one call", calls, task.Done())
+	}
+	stepScheduler(s, 1)
+	if calls != 1 || s.Len() != 0 {
+		t.Errorf("delayed call ran again")
+	}
+}
+
+func TestScheduler_Every(t *testing.T) {
+	s := NewScheduler()
+
+	calls := 0
+	task := s.Every(nil, 0.25, func() { calls++ })
+
+	for i := 0; i < 4; i++ {
+		stepScheduler(s, 0.5)
+	}
+	if calls != 4 {
+		t.Errorf("calls: %d, expected 4 (at most once per frame)", calls)
+	}
+
+	task.Cancel()
+	stepScheduler(s, 1)
+	if calls != 4 || s.Len() != 0 {
+		t.Error("cancelled timer ran")
+	}
+}
+
+func TestScheduler_Pause(t *testing.T) {
+	s := NewScheduler()
+
+	calls := 0
+	s.After(nil, 1, func() { calls++ })
+
+	s.Pause()
+	stepScheduler(s, 2)
+	if calls != 0 {
+		t.Fatal("paused scheduler ran a task")
+	}
+
+	s.Resume()
+	stepScheduler(s, 1)
+	if calls != 1 {
+		t.Errorf("calls: %d, expected 1", calls)
+	}
+}
+
+func TestScheduler_Coroutine(t *testing.T) {
+	s := NewScheduler()
+
+	steps := []string{}
+	ready := false
+
+	task := s.Start(nil, func(co *Coroutine) {
+		steps = append(steps, "start")
+		co.WaitFrame()
+		steps = append(steps, "frame")
+		co.WaitSeconds(1)
+		steps = append(steps, "seconds")
+		co.WaitUntil(func() bool { return ready })
+		steps = append(steps, "until")
+		co.WaitFixedUpdate()
+		steps = append(steps, "fixed")
+	})
+
+	expect := func(n int) {
+		t.Helper()
+		if len(steps) != n {
+			t.Fatalf("steps: %v, expected %d", steps, n)
+		}
+	}
+
+	expect(1)
+	stepScheduler(s, 0.1)
+	expect(2)
+	stepScheduler(s, 0.6)
+	expect(2)
+	stepScheduler(s, 0.6)
+	expect(3)
+	stepScheduler(s, 0.1)
+	expect(3)
+	ready = true
+	stepScheduler(s, 0.1)
+	expect(4)
+	stepScheduler(s, 0.1)
+	expect(4)
+	s.fixedUpdate()
+	expect(5)
+
+	if !task.Done() || s.Len() != 0 {
+		t.Error("finished coroutine still scheduled")
+	}
+}
+
+func TestScheduler_CancelCoroutine(t *testing.T) {
+	s := NewScheduler()
+
+	unwound := false
+	task := s.Start(nil, func(co *Coroutine) {
+		defer func() { unwound = true }()
+
+		for {
+			co.WaitFrame()
+		}
+	})
+
+	stepScheduler(s, 0.1)
+	task.Cancel()
+
+	if !unwound || !task.Done() {
+		t.Error("cancelled coroutine was not unwound")
+	}
+}
+
+func TestScheduler_OwnerLifetime(t *testing.T) {
+	graph := newTestScene(t)
+
+	g := NewGameObject("owner")
+	script := newTestScript(nil)
+	g.AddComponent(script)
+	mustAdd(t, graph, g, nil)
+	graph.Update()
+
+	calls := 0
+	task, err := Every(script, 0.1, func() { calls++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := g.Scene().Scheduler()
+
+	g.SetActive(false)
+	stepScheduler(scheduler, 1)
+	if calls != 0 {
+		t.Error("task of inactive owner ran")
+	}
+
+	g.SetActive(true)
+	stepScheduler(scheduler, 1)
+	if calls != 1 {
+		t.Errorf("calls: %d, expected 1", calls)
+	}
+
+	g.Destroy()
+	graph.FlushCommands()
+
+	if !task.Done() {
+		t.Error("task of destroyed owner was not cancelled")
+	}
+}
+
+func TestScheduler_CoroutineCancelsItself(t *testing.T) {
+	s := NewScheduler()
+
+	var task *Task
+	resumed := false
+	task = s.Start(nil, func(co *Coroutine) {
+		co.WaitFrame()
+		task.Cancel()
+		co.WaitFrame()
+		resumed = true
+	})
+
+	stepScheduler(s, 0.1)
+	stepScheduler(s, 0.1)
+
+	if resumed || !task.Done() || s.Len() != 0 {
+		t.Error("coroutine continued after cancelling itself")
+	}
+}
+
+func TestScheduler_AddedDuringUpdate(t *testing.T) {
+	a := setupTestApp(t)
+
+	var script *testScript
+	frames := []int{}
+	delays := 0
+	unwound := false
+
+	script = newTestScript(func() {
+		if script.updates != 1 {
+			return
+		}
+
+		StartCoroutine(script, func(co *Coroutine) {
+			defer func() { unwound = true }()
+
+			for {
+				frames = append(frames, script.updates)
+				co.WaitFrame()
+			}
+		})
+		After(script, 2*a.Time().FixedTime(), func() { delays = script.updates })
+	})
+
+	s := NewScene("scheduler")
+	s.SetLoadFunc(func() error {
+		g := NewGameObject("script")
+		g.AddComponent(script)
+
+		return s.Graph().AddGameObject(g, nil)
+	})
+
+	if err := a.RegisterScene(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PushScene("scheduler"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.RunTicks(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(frames, []int{1, 2, 3}) {
+		t.Errorf("coroutine frames: %v, expected [1 2 3]", frames)
+	}
+	if delays != 3 {
+		t.Errorf("delayed call ran in frame %d, expected 3", delays)
+	}
+
+	a.PopScene()
+	if err := a.UnregisterScene("scheduler"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !unwound || s.Scheduler().Len() != 0 {
+		t.Error("tasks of an unregistered scene were not cancelled")
+	}
+}
@@ -179,6 +179,10 @@ func (s *Shader) SetUniform(uniformName string, value interface{}) {
 		gl.UniformMatrix3fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), 1, false, &v[0])
 	case mgl32.Mat4:
 		gl.UniformMatrix4fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), 1, false, &v[0])
+	case []mgl32.Mat4:
+		if len(v) != 0 {
+			gl.UniformMatrix4fv(gl.GetUniformLocation(s.programId, gl.Str(uniformName+"\x00")), int32(len(v)), false, &v[0][0])
+		}
 	}
 }
 
@@ -338,3 +342,7 @@ func NewShaderUtilsCopy() *Shader {
 func NewShaderUtilsSkybox() *Shader {
 	return GetAsset().MustGet(AssetNameShader, "utils/skybox").(*Shader)
 }
+
+func NewShaderUtilsNormals() *Shader {
+	return GetAsset().MustGet(AssetNameShader, "utils/normals").(*Shader)
+}
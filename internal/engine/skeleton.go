@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	ErrSkeletonNoJoints  = Error("skeleton has no joints")
+	ErrSkeletonTooLarge  = Error("skeleton has too many joints")
+	ErrSkeletonBindPoses = Error("skeleton bind poses do not match its joints")
+)
+
+type ErrSkeletonJoint string
+
+func (e ErrSkeletonJoint) Error() string {
+	return "skeleton joint " + string(e) + " not found"
+}
+
+// Skeleton maps the joints of a skinned mesh to GameObjects. The joints are
+// ordinary GameObjects, so they are posed by animating their transforms, for
+// example with an Animator using paths like "hips/spine:Transform.rotation".
+type Skeleton struct {
+	root        *GameObject
+	joints      []*GameObject
+	inverseBind []mgl32.Mat4
+	matrices    []mgl32.Mat4
+}
+
+// NewSkeleton creates a skeleton from joint paths relative to the root
+// object. An empty path is the root itself. The inverse bind matrices may be
+// nil, in which case the joints are bound at the origin.
+func NewSkeleton(root *GameObject, joints []string, inverseBind []mgl32.Mat4) (*Skeleton, error) {
+	if len(joints) == 0 {
+		return nil, ErrSkeletonNoJoints
+	}
+	if len(joints) > MaxJoints {
+		return nil, ErrSkeletonTooLarge
+	}
+	if len(inverseBind) == 0 {
+		inverseBind = make([]mgl32.Mat4, len(joints))
+		for i := range inverseBind {
+			inverseBind[i] = mgl32.Ident4()
+		}
+	}
+	if len(inverseBind) != len(joints) {
+		return nil, ErrSkeletonBindPoses
+	}
+
+	s := &Skeleton{
+		root:        root,
+		joints:      make([]*GameObject, len(joints)),
+		inverseBind: inverseBind,
+		matrices:    make([]mgl32.Mat4, len(joints)),
+	}
+
+	for i, path := range joints {
+		joint := root
+		if path != "" {
+			joint = root.Find(path)
+		}
+		if joint == nil {
+			return nil, ErrSkeletonJoint(path)
+		}
+
+		s.joints[i] = joint
+	}
+
+	return s, nil
+}
+
+// NewSkeletonForMesh creates a skeleton for the joints of a skinned mesh.
+func NewSkeletonForMesh(root *GameObject, mesh *Mesh) (*Skeleton, error) {
+	return NewSkeleton(root, mesh.JointNames(), mesh.BindPoses())
+}
+
+// Root returns the object the joint paths are relative to.
+func (s *Skeleton) Root() *GameObject {
+	return s.root
+}
+
+// Len returns the number of joints.
+func (s *Skeleton) Len() int {
+	return len(s.joints)
+}
+
+// Joint returns the object of the joint with the given index.
+func (s *Skeleton) Joint(i int) *GameObject {
+	return s.joints[i]
+}
+
+// Joints returns the objects of all joints, in skin order.
+func (s *Skeleton) Joints() []*GameObject {
+	return s.joints
+}
+
+// Matrices returns the skinning matrices of the joints in the space of the
+// given model matrix, which is the world matrix of the skinned renderer. The
+// returned slice is reused by the next call.
+func (s *Skeleton) Matrices(model mgl32.Mat4) []mgl32.Mat4 {
+	inv := model.Inv()
+
+	for i, joint := range s.joints {
+		s.matrices[i] = inv.Mul4(joint.Transform().RenderMatrix()).Mul4(s.inverseBind[i])
+	}
+
+	return s.matrices
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func newTestRig() (*GameObject, *GameObject, *GameObject) {
+	root := NewGameObject("root")
+	hips := NewGameObject("hips")
+	spine := NewGameObject("spine")
+
+	root.AddChild(hips)
+	hips.SetParent(root)
+	hips.AddChild(spine)
+	spine.SetParent(hips)
+
+	return root, hips, spine
+}
+
+func TestNewSkeleton(t *testing.T) {
+	setupTestApp(t)
+
+	root, hips, spine := newTestRig()
+
+	s, err := NewSkeleton(root, []string{"", "hips", "hips/spine"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 3 || s.Joint(0) != root || s.Joint(1) != hips || s.Joint(2) != spine {
+		t.Errorf("unexpected joints %v", s.Joints())
+	}
+	if len(s.Matrices(mgl32.Ident4())) != 3 {
+		t.Errorf("expected 3 joint matrices")
+	}
+
+	if _, err := NewSkeleton(root, []string{"hips/arm"}, nil); err != ErrSkeletonJoint("hips/arm") {
+		t.Errorf("expected ErrSkeletonJoint, got %v", err)
+	}
+	if _, err := NewSkeleton(root, nil, nil); err != ErrSkeletonNoJoints {
+		t.Errorf("expected ErrSkeletonNoJoints, got %v", err)
+	}
+	if _, err := NewSkeleton(root, []string{"hips"}, make([]mgl32.Mat4, 2)); err != ErrSkeletonBindPoses {
+		t.Errorf("expected ErrSkeletonBindPoses, got %v", err)
+	}
+	if _, err := NewSkeleton(root, make([]string, MaxJoints+1), nil); err != ErrSkeletonTooLarge {
+		t.Errorf("expected ErrSkeletonTooLarge, got %v", err)
+	}
+}
+
+func TestMesh_ValidateSkin(t *testing.T) {
+	setupTestApp(t)
+
+	m := NewMesh()
+	m.SetVertices(make([]mgl32.Vec3, 2))
+
+	if err := m.validateSkin(); err != nil || m.Skinned() {
+		t.Fatalf("unskinned mesh: %v", err)
+	}
+
+	m.SetSkin([][4]uint32{{0}, {1, 0}}, []mgl32.Vec4{{1}, {0.5, 0.5}})
+	if err := m.validateSkin(); err == nil {
+		t.Error("expected error for skin without joints")
+	}
+
+	m.SetJoints([]string{"hips", "hips/spine"}, nil)
+	if err := m.validateSkin(); err != nil {
+		t.Errorf("valid skin: %v", err)
+	}
+
+	m.SetSkin([][4]uint32{{0}, {2}}, []mgl32.Vec4{{1}, {1}})
+	if err := m.validateSkin(); err == nil {
+		t.Error("expected error for out of range joint")
+	}
+
+	// Joints without weight are ignored.
+	m.SetSkin([][4]uint32{{0, 7}, {1}}, []mgl32.Vec4{{1}, {1}})
+	if err := m.validateSkin(); err != nil {
+		t.Errorf("unweighted joint: %v", err)
+	}
+
+	m.SetSkin([][4]uint32{{0}}, []mgl32.Vec4{{1}})
+	if err := m.validateSkin(); err == nil {
+		t.Error("expected error for asymmetric skin")
+	}
+}
+
+func TestAnimator_DrivesJoints(t *testing.T) {
+	setupTestApp(t)
+
+	root, _, spine := newTestRig()
+
+	animator := NewAnimator()
+	root.AddComponent(animator)
+
+	clip := NewAnimationClip("bend")
+	if _, err := clip.AddCurve("hips/spine:Transform.position", InterpolationLinear,
+		Keyframe{Time: 0, Value: []float32{0, 0, 0}},
+		Keyframe{Time: 1, Value: []float32{0, 2, 0}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	animator.AddClip(clip)
+
+	if err := animator.Play("bend"); err != nil {
+		t.Fatal(err)
+	}
+	animator.Step(0.5)
+
+	if p := spine.Transform().Position(); p != (mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("joint position %v, expected [0 1 0]", p)
+	}
+}
@@ -24,6 +24,8 @@ package engine
 
 type ErrSystemNotFound string
 type ErrSystemExists string
+type ErrSystemDependency string
+type ErrSystemCycle string
 
 func (e ErrSystemNotFound) Error() string {
 	return "system " + string(e) + " not found"
@@ -33,6 +35,14 @@ func (e ErrSystemExists) Error() string {
 	return "system " + string(e) + " already exists"
 }
 
+func (e ErrSystemDependency) Error() string {
+	return "system dependency " + string(e) + " not registered"
+}
+
+func (e ErrSystemCycle) Error() string {
+	return "system " + string(e) + " has a cyclic dependency"
+}
+
 // System is an interface representing a major component of the application.
 type System interface {
 	// Setup sets up the System.
@@ -43,4 +53,100 @@ type System interface {
 
 	// Name returns the name of the System.
 	Name() string
-}
\ No newline at end of file
+}
+
+// DependentSystem is implemented by systems which must be set up after other
+// systems. Dependencies are set up first and torn down last.
+type DependentSystem interface {
+	System
+
+	// Dependencies returns the names of the systems this System depends on.
+	Dependencies() []string
+}
+
+// PreUpdateSystem is implemented by systems which run at the start of every
+// frame, before scenes are updated.
+type PreUpdateSystem interface {
+	System
+
+	PreUpdate()
+}
+
+// UpdateSystem is implemented by systems which run every frame, after scenes
+// are updated.
+type UpdateSystem interface {
+	System
+
+	Update()
+}
+
+// FixedUpdateSystem is implemented by systems which run on every fixed update
+// tick, after the fixed update of scenes.
+type FixedUpdateSystem interface {
+	System
+
+	FixedUpdate()
+}
+
+// PostRenderSystem is implemented by systems which run every frame after
+// scenes are rendered. It is also called in headless mode.
+type PostRenderSystem interface {
+	System
+
+	PostRender()
+}
+
+// sortSystems orders systems so every system comes after its dependencies.
+// Systems without a dependency between them keep their registration order.
+func sortSystems(systems []System) ([]System, error) {
+	index := make(map[string]int, len(systems))
+	for i := range systems {
+		index[systems[i].Name()] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(systems))
+	sorted := make([]System, 0, len(systems))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return ErrSystemCycle(systems[i].Name())
+		}
+
+		state[i] = visiting
+
+		if d, ok := systems[i].(DependentSystem); ok {
+			for _, name := range d.Dependencies() {
+				j, ok := index[name]
+				if !ok {
+					return ErrSystemDependency(name)
+				}
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[i] = visited
+		sorted = append(sorted, systems[i])
+
+		return nil
+	}
+
+	for i := range systems {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
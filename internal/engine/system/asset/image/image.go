@@ -25,7 +25,12 @@ package image
 import "github.com/haakenlabs/forge/internal/engine"
 
 func Get(name string) (*engine.Texture2D, error) {
-	return mustHandler().Get(name)
+	h, err := handler()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Get(name)
 }
 
 func MustGet(name string) *engine.Texture2D {
@@ -33,10 +38,20 @@ func MustGet(name string) *engine.Texture2D {
 }
 
 func mustHandler() *engine.ImageHandler {
-	h, err := engine.GetAsset().GetHandler(engine.AssetNameImage)
+	h, err := handler()
 	if err != nil {
 		panic(err)
 	}
 
-	return h.(*engine.ImageHandler)
+	return h
+}
+
+// handler returns the image handler. It is not registered in headless Apps.
+func handler() (*engine.ImageHandler, error) {
+	h, err := engine.GetAsset().GetHandler(engine.AssetNameImage)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.(*engine.ImageHandler), nil
 }
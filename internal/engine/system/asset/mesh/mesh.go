@@ -25,7 +25,12 @@ package mesh
 import "github.com/haakenlabs/forge/internal/engine"
 
 func Get(name string) (*engine.Mesh, error) {
-	return mustHandler().Get(name)
+	h, err := handler()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Get(name)
 }
 
 func MustGet(name string) *engine.Mesh {
@@ -33,10 +38,20 @@ func MustGet(name string) *engine.Mesh {
 }
 
 func mustHandler() *engine.MeshHandler {
-	h, err := engine.GetAsset().GetHandler(engine.AssetNameMesh)
+	h, err := handler()
 	if err != nil {
 		panic(err)
 	}
 
-	return h.(*engine.MeshHandler)
+	return h
+}
+
+// handler returns the mesh handler. It is not registered in headless Apps.
+func handler() (*engine.MeshHandler, error) {
+	h, err := engine.GetAsset().GetHandler(engine.AssetNameMesh)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.(*engine.MeshHandler), nil
 }
@@ -25,7 +25,12 @@ package shader
 import "github.com/haakenlabs/forge/internal/engine"
 
 func Get(name string) (*engine.Shader, error) {
-	return mustHandler().Get(name)
+	h, err := handler()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Get(name)
 }
 
 func MustGet(name string) *engine.Shader {
@@ -33,10 +38,20 @@ func MustGet(name string) *engine.Shader {
 }
 
 func mustHandler() *engine.ShaderHandler {
-	h, err := engine.GetAsset().GetHandler(engine.AssetNameShader)
+	h, err := handler()
 	if err != nil {
 		panic(err)
 	}
 
-	return h.(*engine.ShaderHandler)
+	return h
+}
+
+// handler returns the shader handler. It is not registered in headless Apps.
+func handler() (*engine.ShaderHandler, error) {
+	h, err := engine.GetAsset().GetHandler(engine.AssetNameShader)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.(*engine.ShaderHandler), nil
 }
@@ -25,7 +25,12 @@ package skybox
 import "github.com/haakenlabs/forge/internal/engine"
 
 func Get(name string) (*engine.Skybox, error) {
-	return mustHandler().Get(name)
+	h, err := handler()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Get(name)
 }
 
 func MustGet(name string) *engine.Skybox {
@@ -33,10 +38,20 @@ func MustGet(name string) *engine.Skybox {
 }
 
 func mustHandler() *engine.SkyboxHandler {
-	h, err := engine.GetAsset().GetHandler(engine.AssetNameSkybox)
+	h, err := handler()
 	if err != nil {
 		panic(err)
 	}
 
-	return h.(*engine.SkyboxHandler)
+	return h
+}
+
+// handler returns the skybox handler. It is not registered in headless Apps.
+func handler() (*engine.SkyboxHandler, error) {
+	h, err := engine.GetAsset().GetHandler(engine.AssetNameSkybox)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.(*engine.SkyboxHandler), nil
 }
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package profiler
+
+import (
+	"time"
+
+	"github.com/haakenlabs/forge/internal/engine"
+)
+
+// Begin opens a CPU scope with the given name. Close it with End.
+func Begin(name string) engine.ProfileScope {
+	return engine.GetProfiler().Begin(name)
+}
+
+// BeginGPU opens a scope around GPU commands. Close it with End.
+func BeginGPU(name string) engine.ProfileScope {
+	return engine.GetProfiler().BeginGPU(name)
+}
+
+func Enabled() bool {
+	return engine.GetProfiler().Enabled()
+}
+
+func SetEnabled(enabled bool) {
+	engine.GetProfiler().SetEnabled(enabled)
+}
+
+func StartCapture(frames int) {
+	engine.GetProfiler().StartCapture(frames)
+}
+
+func StopCapture() *engine.ProfileCapture {
+	return engine.GetProfiler().StopCapture()
+}
+
+func LastCapture() *engine.ProfileCapture {
+	return engine.GetProfiler().LastCapture()
+}
+
+func Average(name string) time.Duration {
+	return engine.GetProfiler().Average(name)
+}
+
+func Stats() []engine.ProfileStat {
+	return engine.GetProfiler().Stats()
+}
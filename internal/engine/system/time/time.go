@@ -63,3 +63,31 @@ func LogicTick() {
 func LogicUpdate() bool {
 	return engine.GetTime().LogicUpdate()
 }
+
+func UnscaledDelta() float64 {
+	return engine.GetTime().UnscaledDelta()
+}
+
+func TimeScale() float64 {
+	return engine.GetTime().TimeScale()
+}
+
+func SetTimeScale(scale float64) {
+	engine.GetTime().SetTimeScale(scale)
+}
+
+func Pause() {
+	engine.GetTime().Pause()
+}
+
+func Resume() {
+	engine.GetTime().Resume()
+}
+
+func Paused() bool {
+	return engine.GetTime().Paused()
+}
+
+func Ticks() uint64 {
+	return engine.GetTime().Ticks()
+}
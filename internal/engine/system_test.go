@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+type testSystem struct {
+	name         string
+	dependencies []string
+
+	preUpdates   int
+	updates      int
+	fixedUpdates int
+	postRenders  int
+}
+
+func (s *testSystem) Setup() error           { return nil }
+func (s *testSystem) Teardown()              {}
+func (s *testSystem) Name() string           { return s.name }
+func (s *testSystem) Dependencies() []string { return s.dependencies }
+func (s *testSystem) PreUpdate()             { s.preUpdates++ }
+func (s *testSystem) Update()                { s.updates++ }
+func (s *testSystem) FixedUpdate()           { s.fixedUpdates++ }
+func (s *testSystem) PostRender()            { s.postRenders++ }
+
+func TestSortSystems(t *testing.T) {
+	systems := []System{
+		&testSystem{name: "physics", dependencies: []string{"time"}},
+		&testSystem{name: "audio"},
+		&testSystem{name: "time"},
+		&testSystem{name: "net", dependencies: []string{"physics", "audio"}},
+	}
+
+	sorted, err := sortSystems(systems)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"time", "physics", "audio", "net"}
+	for i := range expected {
+		if sorted[i].Name() != expected[i] {
+			t.Fatalf("system %d: %s, expected %s", i, sorted[i].Name(), expected[i])
+		}
+	}
+}
+
+func TestSortSystems_Errors(t *testing.T) {
+	_, err := sortSystems([]System{
+		&testSystem{name: "a", dependencies: []string{"missing"}},
+	})
+	if _, ok := err.(ErrSystemDependency); !ok {
+		t.Errorf("missing dependency: got %v", err)
+	}
+
+	_, err = sortSystems([]System{
+		&testSystem{name: "a", dependencies: []string{"b"}},
+		&testSystem{name: "b", dependencies: []string{"a"}},
+	})
+	if _, ok := err.(ErrSystemCycle); !ok {
+		t.Errorf("cycle: got %v", err)
+	}
+}
+
+func TestApp_SystemHooks(t *testing.T) {
+	a := setupTestApp(t)
+
+	system := &testSystem{name: "hooks"}
+	systems := a.systems
+	a.systems = append(append([]System{}, systems...), system)
+	defer func() { a.systems = systems }()
+
+	a.maxFrames = 3
+	defer func() { a.maxFrames = 0 }()
+
+	if err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if system.preUpdates != 3 || system.updates != 3 || system.postRenders != 3 {
+		t.Errorf("hooks: pre %d, update %d, post render %d, expected 3",
+			system.preUpdates, system.updates, system.postRenders)
+	}
+}
+
+func TestApp_SystemFixedUpdate(t *testing.T) {
+	a := setupTestApp(t)
+
+	system := &testSystem{name: "fixed"}
+	systems := a.systems
+	a.systems = append(append([]System{}, systems...), system)
+	defer func() { a.systems = systems }()
+
+	if err := a.RunTicks(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if system.fixedUpdates != 4 {
+		t.Errorf("fixed updates: %d, expected 4", system.fixedUpdates)
+	}
+}
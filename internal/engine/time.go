@@ -22,18 +22,31 @@ SOFTWARE.
 
 package engine
 
-import "github.com/go-gl/glfw/v3.2/glfw"
-
 var _ System = &Time{}
 
 const SysNameTime = "time"
 
-// Time implements a time system.
+const (
+	DefaultFixedStep  = float64(0.05)
+	DefaultMaxCatchUp = 5
+)
+
+// Time implements a time system. Frame time is read from a Clock and scaled
+// by the time scale. Fixed updates run at a fixed step from the scaled time,
+// with at most MaxCatchUp ticks per frame.
 type Time struct {
+	clock         Clock
 	frameTime     float64
 	deltaTime     float64
+	unscaledDelta float64
 	interpolation float64
-	nextLogicTick float64
+	accumulator   float64
+	fixedStep     float64
+	scale         float64
+	ticks         uint64
+	maxCatchUp    int
+	started       bool
+	paused        bool
 }
 
 // Setup sets up the System.
@@ -51,52 +64,176 @@ func (t *Time) Name() string {
 	return SysNameTime
 }
 
+// Clock returns the clock of the time system.
+func (t *Time) Clock() Clock {
+	return t.clock
+}
+
 func (t *Time) FrameTime() float64 {
 	return t.frameTime
 }
 
+// DeltaTime returns the scaled duration of the last frame in seconds.
 func (t *Time) DeltaTime() float64 {
 	return t.deltaTime
 }
 
+// UnscaledDelta returns the duration of the last frame in seconds, ignoring
+// the time scale and pause.
+func (t *Time) UnscaledDelta() float64 {
+	return t.unscaledDelta
+}
+
 func (t *Time) FixedTime() float64 {
-	return fixedTime
+	return t.fixedStep
+}
+
+// SetFixedStep sets the duration of a fixed update tick in seconds.
+func (t *Time) SetFixedStep(step float64) {
+	if step > 0 {
+		t.fixedStep = step
+	}
+}
+
+// MaxCatchUp returns the maximum number of fixed update ticks run per frame.
+func (t *Time) MaxCatchUp() int {
+	return t.maxCatchUp
 }
 
+// SetMaxCatchUp sets the maximum number of fixed update ticks run per frame.
+func (t *Time) SetMaxCatchUp(ticks int) {
+	if ticks > 0 {
+		t.maxCatchUp = ticks
+	}
+}
+
+// InterpTime returns how far the simulation is between the last fixed update
+// tick and the next one, in the range [0, 1). It is computed after the fixed
+// updates of each frame.
 func (t *Time) InterpTime() float64 {
 	return t.interpolation
 }
 
+// Interpolate computes the blend factor between the last two fixed update
+// ticks from the remaining accumulated time.
+func (t *Time) Interpolate() {
+	t.interpolation = t.accumulator / t.fixedStep
+
+	if t.interpolation > 1 {
+		t.interpolation = 1
+	}
+}
+
 func (t *Time) Delta() float64 {
 	return t.deltaTime
 }
 
 func (t *Time) Now() float64 {
-	return glfw.GetTime()
+	return t.clock.Now()
+}
+
+// TimeScale returns the time scale.
+func (t *Time) TimeScale() float64 {
+	return t.scale
+}
+
+// SetTimeScale sets the rate at which game time passes relative to the clock.
+func (t *Time) SetTimeScale(scale float64) {
+	if scale >= 0 {
+		t.scale = scale
+	}
+}
+
+// Pause stops game time. Update is still called with a zero delta, but fixed
+// updates do not run.
+func (t *Time) Pause() {
+	t.paused = true
+}
+
+// Resume restarts game time after Pause.
+func (t *Time) Resume() {
+	t.paused = false
+}
+
+// Paused reports if game time is paused.
+func (t *Time) Paused() bool {
+	return t.paused
+}
+
+// Ticks returns the number of fixed update ticks run so far.
+func (t *Time) Ticks() uint64 {
+	return t.ticks
 }
 
 func (t *Time) FrameStart() {
+	now := t.Now()
+
+	if t.started {
+		t.unscaledDelta = now - t.frameTime
+	}
+	t.frameTime = now
+	t.started = true
+
+	t.deltaTime = t.unscaledDelta * t.scale
+	if t.paused {
+		t.deltaTime = 0
+	}
+
+	t.accumulator += t.deltaTime
+}
+
+// FrameStep starts a frame which advances game time by exactly one fixed step,
+// regardless of the clock, time scale or pause.
+func (t *Time) FrameStep() {
 	t.frameTime = t.Now()
+	t.started = true
+	t.unscaledDelta = t.fixedStep
+	t.deltaTime = t.fixedStep
+	t.accumulator = t.fixedStep
 }
 
 func (t *Time) FrameEnd() {
-	t.deltaTime = t.Now() - t.frameTime
+
 }
 
 func (t *Time) LogicTick() {
-	t.nextLogicTick += fixedTime
+	t.accumulator -= t.fixedStep
+	t.ticks++
 }
 
 func (t *Time) LogicUpdate() bool {
-	return t.Now() > t.nextLogicTick
+	return t.accumulator >= t.fixedStep
 }
 
-// NewTime creates a new time system.
-func NewTime() *Time {
-	return &Time{}
+// dropBacklog discards fixed update time which could not be caught up on this
+// frame, so a slow frame does not cause ever growing catch up work.
+func (t *Time) dropBacklog() {
+	if t.accumulator >= t.fixedStep {
+		t.accumulator -= t.fixedStep * float64(int(t.accumulator/t.fixedStep))
+	}
+}
+
+// NewTime creates a new time system using the given clock. If clock is nil, a
+// RealClock is used.
+func NewTime(clock Clock) *Time {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &Time{
+		clock:      clock,
+		fixedStep:  DefaultFixedStep,
+		maxCatchUp: DefaultMaxCatchUp,
+		scale:      1.0,
+	}
 }
 
 // GetTime gets the time system from the current app.
 func GetTime() *Time {
-	return CurrentApp().MustSystem(SysNameTime).(*Time)
+	return CurrentApp().Time()
+}
+
+// Time returns the time system of the App.
+func (a *App) Time() *Time {
+	return a.MustSystem(SysNameTime).(*Time)
 }
@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+)
+
+// runFrame runs the fixed update loop of one frame and returns the number of
+// ticks.
+func runFrame(tm *Time) int {
+	tm.FrameStart()
+
+	loops := 0
+	for tm.LogicUpdate() && loops < tm.MaxCatchUp() {
+		tm.LogicTick()
+		loops++
+	}
+	if loops == tm.MaxCatchUp() {
+		tm.dropBacklog()
+	}
+
+	tm.FrameEnd()
+
+	return loops
+}
+
+func TestTime_FixedStep(t *testing.T) {
+	clock := NewManualClock()
+	tm := NewTime(clock)
+	tm.SetFixedStep(0.25)
+
+	runFrame(tm)
+
+	tests := []struct {
+		advance float64
+		ticks   int
+	}{
+		{0.1, 0},
+		{0.1, 0},
+		{0.1, 1},
+		{0.5, 2},
+		{0.0, 0},
+	}
+
+	for i, v := range tests {
+		clock.Advance(v.advance)
+
+		if ticks := runFrame(tm); ticks != v.ticks {
+			t.Errorf("frame %d: ticks: %d, expected %d", i, ticks, v.ticks)
+		}
+	}
+
+	if tm.Ticks() != 3 {
+		t.Errorf("total ticks: %d, expected 3", tm.Ticks())
+	}
+}
+
+func TestTime_MaxCatchUp(t *testing.T) {
+	clock := NewManualClock()
+	tm := NewTime(clock)
+	tm.SetFixedStep(0.1)
+	tm.SetMaxCatchUp(3)
+
+	runFrame(tm)
+	clock.Advance(10)
+
+	if ticks := runFrame(tm); ticks != 3 {
+		t.Errorf("ticks: %d, expected 3", ticks)
+	}
+
+	// The backlog is dropped, so the next frame does not catch up.
+	if ticks := runFrame(tm); ticks != 0 {
+		t.Errorf("ticks after backlog: %d, expected 0", ticks)
+	}
+}
+
+func TestTime_ScaleAndPause(t *testing.T) {
+	clock := NewManualClock()
+	tm := NewTime(clock)
+	tm.SetFixedStep(0.1)
+
+	runFrame(tm)
+
+	tm.SetTimeScale(0.5)
+	clock.Advance(0.4)
+
+	if ticks := runFrame(tm); ticks != 2 {
+		t.Errorf("scaled ticks: %d, expected 2", ticks)
+	}
+	if tm.DeltaTime() != 0.2 || tm.UnscaledDelta() != 0.4 {
+		t.Errorf("delta: %v unscaled: %v, expected 0.2 and 0.4", tm.DeltaTime(), tm.UnscaledDelta())
+	}
+
+	tm.Pause()
+	clock.Advance(1)
+
+	if ticks := runFrame(tm); ticks != 0 {
+		t.Errorf("paused ticks: %d, expected 0", ticks)
+	}
+	if tm.DeltaTime() != 0 {
+		t.Errorf("paused delta: %v, expected 0", tm.DeltaTime())
+	}
+
+	tm.Resume()
+	tm.SetTimeScale(1)
+	clock.Advance(0.1)
+
+	if ticks := runFrame(tm); ticks != 1 {
+		t.Errorf("resumed ticks: %d, expected 1", ticks)
+	}
+}
+
+func TestTime_Interpolate(t *testing.T) {
+	clock := NewManualClock()
+	tm := NewTime(clock)
+	tm.SetFixedStep(0.5)
+
+	runFrame(tm)
+	clock.Advance(0.625)
+	runFrame(tm)
+
+	if tm.InterpTime() != 0 {
+		t.Errorf("interpolation before Interpolate: %v, expected 0", tm.InterpTime())
+	}
+
+	tm.Interpolate()
+
+	if tm.InterpTime() != 0.25 {
+		t.Errorf("interpolation: %v, expected 0.25", tm.InterpTime())
+	}
+}
@@ -31,6 +31,7 @@ type Transform interface {
 
 	ModelMatrix() mgl32.Mat4
 	ActiveMatrix() mgl32.Mat4
+	RenderMatrix() mgl32.Mat4
 	Rotation() mgl32.Quat
 	Position() mgl32.Vec3
 	Scale() mgl32.Vec3
@@ -47,9 +48,27 @@ type BaseTransform struct {
 
 	modelMatrix  mgl32.Mat4
 	activeMatrix mgl32.Mat4
+	renderMatrix mgl32.Mat4
 	rotation     mgl32.Quat
 	position     mgl32.Vec3
 	scale        mgl32.Vec3
+	previous     transformState
+	interpolate  bool
+}
+
+// transformState is the local state of a transform at the start of the last
+// fixed update tick.
+type transformState struct {
+	rotation mgl32.Quat
+	position mgl32.Vec3
+	scale    mgl32.Vec3
+}
+
+// interpolatedTransform is implemented by transforms which support
+// interpolation between fixed update ticks.
+type interpolatedTransform interface {
+	snapshot()
+	updateRenderMatrix(alpha float32)
 }
 
 func (t *BaseTransform) ModelMatrix() mgl32.Mat4 {
@@ -60,6 +79,65 @@ func (t *BaseTransform) ActiveMatrix() mgl32.Mat4 {
 	return t.activeMatrix
 }
 
+// RenderMatrix returns the world matrix used for rendering. For transforms
+// with interpolation enabled, it is blended between the previous and the
+// current fixed update tick. Otherwise it follows ActiveMatrix, including any
+// interpolation of its ancestors.
+func (t *BaseTransform) RenderMatrix() mgl32.Mat4 {
+	return t.renderMatrix
+}
+
+// Interpolate reports if interpolation between fixed update ticks is enabled.
+func (t *BaseTransform) Interpolate() bool {
+	return t.interpolate
+}
+
+// SetInterpolate enables or disables interpolation between fixed update ticks.
+// Enable it for objects which are moved in FixedUpdate.
+func (t *BaseTransform) SetInterpolate(interpolate bool) {
+	t.interpolate = interpolate
+	t.ResetInterpolation()
+}
+
+// ResetInterpolation discards the previous tick state, so the next frame is
+// rendered at the current state. Call it after teleporting an object.
+func (t *BaseTransform) ResetInterpolation() {
+	t.snapshot()
+	t.renderMatrix = t.activeMatrix
+}
+
+func (t *BaseTransform) snapshot() {
+	t.previous = transformState{
+		rotation: t.rotation,
+		position: t.position,
+		scale:    t.scale,
+	}
+}
+
+func (t *BaseTransform) updateRenderMatrix(alpha float32) {
+	local := t.modelMatrix
+
+	if t.interpolate {
+		p := t.previous
+		position := p.position.Add(t.position.Sub(p.position).Mul(alpha))
+		scale := p.scale.Add(t.scale.Sub(p.scale).Mul(alpha))
+		rotation := mgl32.QuatSlerp(p.rotation, t.rotation, alpha)
+
+		tp := mgl32.Translate3D(position.X(), position.Y(), position.Z())
+		ts := mgl32.Scale3D(scale.X(), scale.Y(), scale.Z())
+
+		local = tp.Mul4(rotation.Mat4().Mul4(ts))
+	}
+
+	t.renderMatrix = local
+
+	if t.GameObject() != nil {
+		if parent := t.GameObject().Parent(); parent != nil {
+			t.renderMatrix = parent.Transform().RenderMatrix().Mul4(local)
+		}
+	}
+}
+
 func (t *BaseTransform) Rotation() mgl32.Quat {
 	return t.rotation
 }
@@ -111,13 +189,18 @@ func (t *BaseTransform) Recompute(updateChildren bool) {
 		if parent := t.GameObject().Parent(); parent != nil {
 			t.activeMatrix = parent.Transform().ActiveMatrix().Mul4(t.modelMatrix)
 		}
+	}
+
+	// Interpolated transforms are updated once per frame by the scene graph.
+	if !t.interpolate {
+		t.renderMatrix = t.activeMatrix
+	}
 
-		if updateChildren {
-			childComponents := t.GameObject().ComponentsInChildren()
-			for idx := range childComponents {
-				if child, ok := childComponents[idx].(Transform); ok {
-					child.Recompute(false)
-				}
+	if t.GameObject() != nil && updateChildren {
+		childComponents := t.GameObject().ComponentsInChildren()
+		for idx := range childComponents {
+			if child, ok := childComponents[idx].(Transform); ok {
+				child.Recompute(false)
 			}
 		}
 	}
@@ -133,6 +216,31 @@ func NewTransform() *BaseTransform {
 	GetInstance().MustAssign(t)
 
 	t.Recompute(false)
+	t.snapshot()
 
 	return t
 }
+
+// decomposeMatrix splits an affine transformation matrix into its position,
+// rotation and scale components. Shear is not preserved.
+func decomposeMatrix(m mgl32.Mat4) (mgl32.Vec3, mgl32.Quat, mgl32.Vec3) {
+	position := m.Col(3).Vec3()
+	scale := mgl32.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+
+	// A negative determinant means the matrix mirrors, fold it into X.
+	if m.Det() < 0 {
+		scale[0] = -scale[0]
+	}
+
+	r := mgl32.Ident4()
+	for i := 0; i < 3; i++ {
+		if scale[i] == 0 {
+			continue
+		}
+
+		col := m.Col(i).Vec3().Mul(1 / scale[i])
+		r.SetCol(i, col.Vec4(0))
+	}
+
+	return position, mgl32.Mat4ToQuat(r).Normalize(), scale
+}
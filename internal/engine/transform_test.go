@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testMover moves its GameObject along the x axis every fixed update.
+type testMover struct {
+	BaseScriptComponent
+
+	step float32
+}
+
+func (c *testMover) FixedUpdate() {
+	t := c.GetTransform()
+	t.SetPosition(t.Position().Add(mgl32.Vec3{c.step, 0, 0}))
+}
+
+func newTestMover(step float32) *testMover {
+	c := &testMover{step: step}
+
+	c.SetName("TestMover")
+	GetInstance().MustAssign(c)
+
+	return c
+}
+
+func TestSceneGraph_InterpolateTransforms(t *testing.T) {
+	s := newTestScene(t)
+
+	mover := NewGameObject("mover")
+	transform := mover.Transform().(*BaseTransform)
+	transform.SetInterpolate(true)
+	mover.AddComponent(newTestMover(2))
+
+	child := NewGameObject("child")
+	child.Transform().SetPosition(mgl32.Vec3{0, 1, 0})
+	mover.AddChild(child)
+	child.SetParent(mover)
+
+	still := NewGameObject("still")
+	still.Transform().SetPosition(mgl32.Vec3{0, 0, 5})
+
+	mustAdd(t, s, mover, nil)
+	mustAdd(t, s, still, nil)
+
+	s.snapshotTransforms()
+	s.SendMessage(MessageFixedUpdate)
+	s.interpolateTransforms(0.5)
+
+	if p := mover.Transform().ActiveMatrix().Col(3).Vec3(); p != (mgl32.Vec3{2, 0, 0}) {
+		t.Errorf("active position %v, expected [2 0 0]", p)
+	}
+
+	tests := []struct {
+		object   *GameObject
+		expected mgl32.Mat4
+	}{
+		{mover, mgl32.Translate3D(1, 0, 0)},
+		{child, mgl32.Translate3D(1, 1, 0)},
+		{still, mgl32.Translate3D(0, 0, 5)},
+	}
+
+	for _, test := range tests {
+		if m := test.object.Transform().RenderMatrix(); !matrixNear(m, test.expected) {
+			t.Errorf("%s render matrix at alpha 0.5:\n%v\nexpected\n%v", test.object.Name(), m, test.expected)
+		}
+	}
+
+	s.interpolateTransforms(1)
+
+	if m := child.Transform().RenderMatrix(); !matrixNear(m, mgl32.Translate3D(2, 1, 0)) {
+		t.Errorf("child render matrix at alpha 1:\n%v", m)
+	}
+
+	// The next tick blends from the state of the previous one.
+	s.snapshotTransforms()
+	s.SendMessage(MessageFixedUpdate)
+	s.interpolateTransforms(0.25)
+
+	if m := mover.Transform().RenderMatrix(); !matrixNear(m, mgl32.Translate3D(2.5, 0, 0)) {
+		t.Errorf("render matrix after second tick:\n%v", m)
+	}
+
+	// Resetting skips the blend until the next tick.
+	transform.ResetInterpolation()
+	s.interpolateTransforms(0)
+
+	if m := mover.Transform().RenderMatrix(); !matrixNear(m, mgl32.Translate3D(4, 0, 0)) {
+		t.Errorf("render matrix after reset:\n%v", m)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+var _ Transition = &FadeTransition{}
+var _ Transition = &LoadingSceneTransition{}
+
+// Transition is shown while App.SwitchScene loads the target scene. All
+// methods are called on the main thread.
+type Transition interface {
+	// Begin is called when the switch starts.
+	Begin(a *App, op *SceneLoadOperation)
+
+	// Out is called every frame before the switch. It returns true once the
+	// current scene is hidden. The switch happens once Out returns true and
+	// the target scene has loaded.
+	Out(delta float64) bool
+
+	// Switched is called after the target scene has been activated.
+	Switched(a *App)
+
+	// In is called every frame after the switch. It returns true once the
+	// target scene is fully shown.
+	In(delta float64) bool
+
+	// End is called when the transition is complete.
+	End(a *App)
+}
+
+// FadeTransition fades the cameras of the active scene to black, switches
+// scenes, then fades the new scene in.
+type FadeTransition struct {
+	effect   *fadeEffect
+	cameras  []*Camera
+	duration float64
+	fade     float32
+}
+
+// Fade returns the current fade amount, from 0 (visible) to 1 (black).
+func (t *FadeTransition) Fade() float32 {
+	return t.fade
+}
+
+// Begin attaches the fade to the cameras of the active scene.
+func (t *FadeTransition) Begin(a *App, _ *SceneLoadOperation) {
+	t.fade = 0
+	t.attach(a.ActiveScene())
+}
+
+// Out fades to black.
+func (t *FadeTransition) Out(delta float64) bool {
+	t.fade += t.step(delta)
+	if t.fade >= 1 {
+		t.fade = 1
+		return true
+	}
+
+	return false
+}
+
+// Switched moves the fade to the cameras of the new scene.
+func (t *FadeTransition) Switched(a *App) {
+	t.detach()
+	t.attach(a.ActiveScene())
+}
+
+// In fades back from black.
+func (t *FadeTransition) In(delta float64) bool {
+	t.fade -= t.step(delta)
+	if t.fade <= 0 {
+		t.fade = 0
+		return true
+	}
+
+	return false
+}
+
+// End removes the fade from the cameras.
+func (t *FadeTransition) End(_ *App) {
+	t.detach()
+}
+
+func (t *FadeTransition) step(delta float64) float32 {
+	if t.duration <= 0 {
+		return 1
+	}
+
+	return float32(delta / (t.duration / 2))
+}
+
+func (t *FadeTransition) attach(s *Scene) {
+	if s == nil {
+		return
+	}
+
+	if s.Graph().Dirty() {
+		s.Graph().Update()
+	}
+
+	t.cameras = append(t.cameras[:0], s.cameras...)
+	for i := range t.cameras {
+		t.cameras[i].AddEffect(t.effect)
+	}
+}
+
+func (t *FadeTransition) detach() {
+	for i := range t.cameras {
+		t.cameras[i].RemoveEffect(t.effect)
+	}
+
+	t.cameras = t.cameras[:0]
+}
+
+// NewFadeTransition creates a new FadeTransition. The duration in seconds
+// covers both fading out and fading in.
+func NewFadeTransition(duration float64) *FadeTransition {
+	t := &FadeTransition{
+		duration: duration,
+		cameras:  []*Camera{},
+	}
+
+	t.effect = &fadeEffect{transition: t}
+
+	return t
+}
+
+// fadeEffect darkens the camera image by the fade amount of its transition.
+type fadeEffect struct {
+	transition *FadeTransition
+	shader     *Shader
+}
+
+func (e *fadeEffect) Render(w EffectWriter) {
+	if e.shader == nil {
+		e.shader = GetAsset().MustGet(AssetNameShader, "effect/fade").(*Shader)
+	}
+
+	e.shader.Bind()
+	e.shader.SetSubroutine(ShaderComponentFragment, "pass_0")
+	e.shader.SetUniform("f_fade", e.transition.fade)
+
+	w.EffectPass()
+
+	e.shader.Unbind()
+}
+
+func (e *fadeEffect) Type() EffectType {
+	return EffectTypeLDR
+}
+
+// LoadingSceneTransition shows a loading scene on top of the active scene
+// while the target scene loads. The loading scene should be cheap to load, it
+// is loaded synchronously. Scripts in the loading scene can read the progress
+// from App.Loading.
+type LoadingSceneTransition struct {
+	name string
+}
+
+// Begin pushes the loading scene.
+func (t *LoadingSceneTransition) Begin(a *App, _ *SceneLoadOperation) {
+	if err := a.PushScene(t.name); err != nil {
+		logrus.Error("loading scene transition: ", err)
+	}
+}
+
+// Out returns true, the loading scene is shown immediately.
+func (t *LoadingSceneTransition) Out(_ float64) bool {
+	return true
+}
+
+// Switched does nothing, the loading scene is removed by the switch.
+func (t *LoadingSceneTransition) Switched(_ *App) {}
+
+// In returns true, the target scene is shown immediately.
+func (t *LoadingSceneTransition) In(_ float64) bool {
+	return true
+}
+
+// End does nothing.
+func (t *LoadingSceneTransition) End(_ *App) {}
+
+// NewLoadingSceneTransition creates a new LoadingSceneTransition showing the
+// registered scene with the given name.
+func NewLoadingSceneTransition(name string) *LoadingSceneTransition {
+	return &LoadingSceneTransition{
+		name: name,
+	}
+}
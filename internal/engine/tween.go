@@ -0,0 +1,558 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	forgemath "github.com/haakenlabs/forge/internal/math"
+)
+
+// LoopMode determines how a looping tween repeats.
+type LoopMode int
+
+const (
+	// LoopRestart restarts every cycle from the beginning.
+	LoopRestart LoopMode = iota
+
+	// LoopYoyo plays every other cycle backwards.
+	LoopYoyo
+)
+
+// Tweenable is the set of value types a Tween can animate.
+type Tweenable interface {
+	float32 | float64 | mgl32.Vec2 | mgl32.Vec3 | mgl32.Vec4 | mgl32.Quat | Color
+}
+
+// Tweener is a Tween or a group of tweens. Tweeners are played with
+// Scheduler.Tween or PlayTween, and can be nested in sequences and parallel
+// groups.
+type Tweener interface {
+	// Duration returns the duration of all loops of the tweener, which is
+	// infinite if it loops forever.
+	Duration() float64
+
+	// seek applies the state at time t.
+	seek(t float64)
+
+	// rewind resets completion and captured start values, so the tweener
+	// runs its callbacks and reads its start values again when replayed.
+	rewind()
+}
+
+// tweenLoop holds the loop and completion state shared by all tweeners.
+type tweenLoop struct {
+	onComplete func()
+	loops      int
+	lastCycle  int
+	mode       LoopMode
+	completed  bool
+}
+
+// Tween animates a value between two values with an easing function.
+type Tween struct {
+	tweenLoop
+
+	apply    func(t float32)
+	capture  func()
+	ease     forgemath.EaseFunc
+	duration float64
+	captured bool
+}
+
+// Sequence plays tweeners one after another.
+type Sequence struct {
+	tweenLoop
+
+	children []Tweener
+	reached  []bool
+	times    []float64
+	last     float64
+}
+
+// Parallel plays tweeners at the same time. Its duration is the duration of
+// the longest tweener.
+type Parallel struct {
+	tweenLoop
+
+	children []Tweener
+	times    []float64
+}
+
+func (l *tweenLoop) setLoops(loops int, mode LoopMode) {
+	if loops == 0 {
+		loops = 1
+	}
+
+	l.loops = loops
+	l.mode = mode
+}
+
+func (l *tweenLoop) total(cycle float64) float64 {
+	if l.loops < 0 {
+		return math.Inf(1)
+	}
+
+	return cycle * float64(l.loops)
+}
+
+// local maps the time t of all loops to the time within the current cycle.
+func (l *tweenLoop) local(t, cycle float64) float64 {
+	if cycle <= 0 {
+		return 0
+	}
+
+	var n, local float64
+	if total := l.total(cycle); t >= total {
+		n, local = float64(l.loops-1), cycle
+	} else {
+		n = math.Floor(t / cycle)
+		local = t - n*cycle
+	}
+
+	if l.mode == LoopYoyo && int64(n)%2 == 1 {
+		local = cycle - local
+	}
+
+	return local
+}
+
+// crossed reports if t is in a different cycle than the previous call, and
+// returns the local time the previous cycle ended at.
+func (l *tweenLoop) crossed(t, cycle float64) (float64, bool) {
+	n := 0
+	if cycle > 0 {
+		if total := l.total(cycle); t >= total {
+			n = l.loops - 1
+		} else {
+			n = int(math.Floor(t / cycle))
+		}
+	}
+
+	last := l.lastCycle
+	l.lastCycle = n
+
+	if n == last {
+		return 0, false
+	}
+	if l.mode == LoopYoyo && last%2 == 1 {
+		return 0, true
+	}
+
+	return cycle, true
+}
+
+// finish runs the completion callback once t reaches the end.
+func (l *tweenLoop) finish(t, total float64) {
+	if t < total {
+		l.completed = false
+		return
+	}
+
+	if !l.completed {
+		l.completed = true
+
+		if l.onComplete != nil {
+			l.onComplete()
+		}
+	}
+}
+
+// Duration returns the duration of all loops of the tween.
+func (t *Tween) Duration() float64 {
+	return t.total(t.duration)
+}
+
+// SetEase sets the easing function of the tween.
+func (t *Tween) SetEase(ease forgemath.EaseFunc) *Tween {
+	t.ease = ease
+	return t
+}
+
+// SetLoops sets the number of times the tween is played, with -1 looping
+// forever.
+func (t *Tween) SetLoops(loops int, mode LoopMode) *Tween {
+	t.setLoops(loops, mode)
+	return t
+}
+
+// OnComplete sets a function called when the tween completes.
+func (t *Tween) OnComplete(fn func()) *Tween {
+	t.onComplete = fn
+	return t
+}
+
+func (t *Tween) seek(time float64) {
+	if !t.captured {
+		t.captured = true
+
+		if t.capture != nil {
+			t.capture()
+		}
+	}
+
+	p := 1.0
+	if t.duration > 0 {
+		p = t.local(time, t.duration) / t.duration
+	}
+
+	if t.apply != nil {
+		t.apply(float32(t.ease(p)))
+	}
+
+	t.finish(time, t.Duration())
+}
+
+func (t *Tween) rewind() {
+	t.completed = false
+	t.captured = false
+}
+
+// Duration returns the duration of all loops of the sequence.
+func (s *Sequence) Duration() float64 {
+	return s.total(s.cycle())
+}
+
+// Append adds a tweener to the end of the sequence.
+func (s *Sequence) Append(tw Tweener) *Sequence {
+	s.children = append(s.children, tw)
+	s.reached = append(s.reached, false)
+	s.times = append(s.times, 0)
+
+	return s
+}
+
+// AppendInterval adds a pause to the end of the sequence.
+func (s *Sequence) AppendInterval(seconds float64) *Sequence {
+	return s.Append(NewDelay(seconds))
+}
+
+// AppendCallback adds a function call to the end of the sequence.
+func (s *Sequence) AppendCallback(fn func()) *Sequence {
+	return s.Append(NewDelay(0).OnComplete(fn))
+}
+
+// SetLoops sets the number of times the sequence is played, with -1 looping
+// forever.
+func (s *Sequence) SetLoops(loops int, mode LoopMode) *Sequence {
+	s.setLoops(loops, mode)
+	return s
+}
+
+// OnComplete sets a function called when the sequence completes.
+func (s *Sequence) OnComplete(fn func()) *Sequence {
+	s.onComplete = fn
+	return s
+}
+
+func (s *Sequence) cycle() float64 {
+	d := 0.0
+	for i := range s.children {
+		d += s.children[i].Duration()
+	}
+
+	return d
+}
+
+func (s *Sequence) seek(t float64) {
+	cycle := s.cycle()
+
+	// Finish the previous cycle first, so its callbacks run.
+	if end, ok := s.crossed(t, cycle); ok {
+		s.seekLocal(end)
+	}
+
+	s.seekLocal(s.local(t, cycle))
+	s.finish(t, s.Duration())
+}
+
+func (s *Sequence) seekLocal(local float64) {
+	if local >= s.last {
+		start := 0.0
+		for i := range s.children {
+			s.seekChild(i, local, start)
+			start += s.children[i].Duration()
+		}
+	} else {
+		// Rewind later children first, so earlier ones set the final state.
+		start := s.cycle()
+		for i := len(s.children) - 1; i >= 0; i-- {
+			start -= s.children[i].Duration()
+			s.seekChild(i, local, start)
+		}
+	}
+
+	s.last = local
+}
+
+func (s *Sequence) seekChild(i int, local, start float64) {
+	c := s.children[i]
+
+	if local < start {
+		// Children which have not been reached keep their state, so they
+		// capture their start values when reached.
+		if s.reached[i] {
+			c.seek(0)
+			c.rewind()
+			s.reached[i] = false
+		}
+		return
+	}
+
+	t := math.Min(local-start, c.Duration())
+	if !s.reached[i] || t != s.times[i] {
+		c.seek(t)
+		s.reached[i] = true
+		s.times[i] = t
+	}
+}
+
+func (s *Sequence) rewind() {
+	s.completed = false
+	s.lastCycle = 0
+	s.last = 0
+
+	for i := range s.children {
+		s.children[i].rewind()
+		s.reached[i] = false
+	}
+}
+
+// Duration returns the duration of all loops of the group.
+func (p *Parallel) Duration() float64 {
+	return p.total(p.cycle())
+}
+
+// Add adds a tweener to the group.
+func (p *Parallel) Add(tw Tweener) *Parallel {
+	p.children = append(p.children, tw)
+	p.times = append(p.times, -1)
+
+	return p
+}
+
+// SetLoops sets the number of times the group is played, with -1 looping
+// forever.
+func (p *Parallel) SetLoops(loops int, mode LoopMode) *Parallel {
+	p.setLoops(loops, mode)
+	return p
+}
+
+// OnComplete sets a function called when all tweeners of the group complete.
+func (p *Parallel) OnComplete(fn func()) *Parallel {
+	p.onComplete = fn
+	return p
+}
+
+func (p *Parallel) cycle() float64 {
+	d := 0.0
+	for i := range p.children {
+		d = math.Max(d, p.children[i].Duration())
+	}
+
+	return d
+}
+
+func (p *Parallel) seek(t float64) {
+	cycle := p.cycle()
+
+	// Finish the previous cycle first, so its callbacks run.
+	if end, ok := p.crossed(t, cycle); ok {
+		p.seekLocal(end)
+	}
+
+	p.seekLocal(p.local(t, cycle))
+	p.finish(t, p.Duration())
+}
+
+func (p *Parallel) seekLocal(local float64) {
+	for i := range p.children {
+		// Seeking back resets completion by itself. Rewinding would make
+		// children capture their start values again mid-animation.
+		ct := math.Min(local, p.children[i].Duration())
+		if ct != p.times[i] {
+			p.children[i].seek(ct)
+			p.times[i] = ct
+		}
+	}
+}
+
+func (p *Parallel) rewind() {
+	p.completed = false
+	p.lastCycle = 0
+
+	for i := range p.children {
+		p.children[i].rewind()
+		p.times[i] = -1
+	}
+}
+
+// Tween plays the tweener until it completes. The tweener is rewound and
+// starts at time zero immediately, and advances with the scaled delta time of
+// the scene.
+func (s *Scheduler) Tween(owner Component, tw Tweener) *Task {
+	elapsed := 0.0
+	tw.rewind()
+	tw.seek(0)
+
+	return s.Run(owner, func(delta float64) bool {
+		elapsed += delta
+
+		if d := tw.Duration(); elapsed >= d {
+			tw.seek(d)
+			return true
+		}
+
+		tw.seek(elapsed)
+
+		return false
+	})
+}
+
+// PlayTween plays the tweener on the scheduler of the scene owning the
+// component's GameObject.
+func PlayTween(owner Component, tw Tweener) (*Task, error) {
+	g := owner.GameObject()
+	if g == nil || g.Scene() == nil {
+		return nil, ErrNotInScene
+	}
+
+	return g.Scene().Scheduler().Tween(owner, tw), nil
+}
+
+func newTween(duration float64) *Tween {
+	t := &Tween{
+		ease:     forgemath.EaseNone,
+		duration: duration,
+	}
+	t.loops = 1
+
+	return t
+}
+
+// NewTween creates a tween which animates from one value to another over the
+// given duration in seconds, passing every value to set.
+func NewTween[T Tweenable](from, to T, duration float64, set func(T)) *Tween {
+	t := newTween(duration)
+	t.apply = func(p float32) {
+		set(lerpTweenable(from, to, p))
+	}
+
+	return t
+}
+
+// TweenTo creates a tween which animates from the value returned by get to
+// the given value. The start value is read when the tween first runs, so
+// tweens of the same property can be chained in a sequence.
+func TweenTo[T Tweenable](get func() T, set func(T), to T, duration float64) *Tween {
+	var from T
+
+	t := newTween(duration)
+	t.capture = func() {
+		from = get()
+	}
+	t.apply = func(p float32) {
+		set(lerpTweenable(from, to, p))
+	}
+
+	return t
+}
+
+// NewDelay creates a tween which does nothing for the given duration.
+func NewDelay(duration float64) *Tween {
+	return newTween(duration)
+}
+
+// NewSequence creates a sequence of the given tweeners.
+func NewSequence(children ...Tweener) *Sequence {
+	s := &Sequence{}
+	s.loops = 1
+
+	for i := range children {
+		s.Append(children[i])
+	}
+
+	return s
+}
+
+// NewParallel creates a parallel group of the given tweeners.
+func NewParallel(children ...Tweener) *Parallel {
+	p := &Parallel{}
+	p.loops = 1
+
+	for i := range children {
+		p.Add(children[i])
+	}
+
+	return p
+}
+
+// TweenPosition creates a tween which moves the transform to a position.
+func TweenPosition(t Transform, to mgl32.Vec3, duration float64) *Tween {
+	return TweenTo(t.Position, t.SetPosition, to, duration)
+}
+
+// TweenRotation creates a tween which rotates the transform to a rotation.
+func TweenRotation(t Transform, to mgl32.Quat, duration float64) *Tween {
+	return TweenTo(t.Rotation, t.SetRotation, to, duration)
+}
+
+// TweenScale creates a tween which scales the transform to a scale.
+func TweenScale(t Transform, to mgl32.Vec3, duration float64) *Tween {
+	return TweenTo(t.Scale, t.SetScale, to, duration)
+}
+
+// lerpTweenable interpolates between a and b. The factor is not clamped, so
+// easing functions may overshoot. Rotations are interpolated spherically.
+func lerpTweenable[T Tweenable](a, b T, t float32) T {
+	var v interface{}
+
+	switch a := interface{}(a).(type) {
+	case float32:
+		v = lerp32(a, interface{}(b).(float32), t)
+	case float64:
+		v = a + (interface{}(b).(float64)-a)*float64(t)
+	case mgl32.Vec2:
+		b := interface{}(b).(mgl32.Vec2)
+		v = mgl32.Vec2{lerp32(a[0], b[0], t), lerp32(a[1], b[1], t)}
+	case mgl32.Vec3:
+		b := interface{}(b).(mgl32.Vec3)
+		v = mgl32.Vec3{lerp32(a[0], b[0], t), lerp32(a[1], b[1], t), lerp32(a[2], b[2], t)}
+	case mgl32.Vec4:
+		b := interface{}(b).(mgl32.Vec4)
+		v = mgl32.Vec4{lerp32(a[0], b[0], t), lerp32(a[1], b[1], t), lerp32(a[2], b[2], t), lerp32(a[3], b[3], t)}
+	case mgl32.Quat:
+		v = mgl32.QuatSlerp(a, interface{}(b).(mgl32.Quat), t)
+	case Color:
+		b := interface{}(b).(Color)
+		v = Color{lerp32(a.R, b.R, t), lerp32(a.G, b.G, t), lerp32(a.B, b.B, t), lerp32(a.A, b.A, t)}
+	}
+
+	return v.(T)
+}
+
+func lerp32(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
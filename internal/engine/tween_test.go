@@ -0,0 +1,212 @@
+/*
+Copyright (c) 2017 HaakenLabs
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	forgemath "github.com/haakenlabs/forge/internal/math"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestTween_Values(t *testing.T) {
+	v := 0.0
+	tw := NewTween(0.0, 10.0, 2, func(x float64) { v = x })
+
+	for _, c := range []struct{ t, v float64 }{{0, 0}, {1, 5}, {2, 10}, {3, 10}} {
+		tw.seek(math.Min(c.t, tw.Duration()))
+		if !approx(v, c.v) {
+			t.Errorf("t=%v: %v, expected %v", c.t, v, c.v)
+		}
+	}
+
+	var vec mgl32.Vec3
+	NewTween(mgl32.Vec3{}, mgl32.Vec3{2, 4, 6}, 1, func(x mgl32.Vec3) { vec = x }).seek(0.5)
+	if vec != (mgl32.Vec3{1, 2, 3}) {
+		t.Errorf("vec3: %v", vec)
+	}
+
+	var c Color
+	NewTween(Color{}, Color{1, 1, 1, 1}, 1, func(x Color) { c = x }).seek(0.25)
+	if c != (Color{0.25, 0.25, 0.25, 0.25}) {
+		t.Errorf("color: %v", c)
+	}
+}
+
+func TestTween_Ease(t *testing.T) {
+	v := 0.0
+	tw := NewTween(0.0, 1.0, 1, func(x float64) { v = x }).SetEase(forgemath.EaseInQuad)
+
+	tw.seek(0.5)
+	if !approx(v, 0.25) {
+		t.Errorf("eased value %v, expected 0.25", v)
+	}
+}
+
+func TestTween_Yoyo(t *testing.T) {
+	v := 0.0
+	completed := 0
+	tw := NewTween(0.0, 1.0, 1, func(x float64) { v = x }).
+		SetLoops(2, LoopYoyo).
+		OnComplete(func() { completed++ })
+
+	if tw.Duration() != 2 {
+		t.Fatalf("duration %v, expected 2", tw.Duration())
+	}
+
+	tw.seek(0.5)
+	if !approx(v, 0.5) {
+		t.Errorf("forward: %v", v)
+	}
+	tw.seek(1.25)
+	if !approx(v, 0.75) {
+		t.Errorf("backward: %v, expected 0.75", v)
+	}
+	tw.seek(2)
+	if !approx(v, 0) || completed != 1 {
+		t.Errorf("end: %v, completed %d", v, completed)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	v := 0.0
+	calls := 0
+
+	s := NewSequence(
+		TweenTo(func() float64 { return v }, func(x float64) { v = x }, 1, 1),
+		NewDelay(1),
+		TweenTo(func() float64 { return v }, func(x float64) { v = x }, 3, 1),
+	).AppendCallback(func() { calls++ })
+
+	if s.Duration() != 3 {
+		t.Fatalf("duration %v, expected 3", s.Duration())
+	}
+
+	for _, c := range []struct{ t, v float64 }{{0.5, 0.5}, {1.5, 1}, {2.5, 2}, {3, 3}} {
+		s.seek(c.t)
+		if !approx(v, c.v) {
+			t.Errorf("t=%v: %v, expected %v", c.t, v, c.v)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("callback calls: %d, expected 1", calls)
+	}
+}
+
+func TestSequence_Loop(t *testing.T) {
+	v := 0.0
+	calls := 0
+
+	s := NewSequence(
+		NewTween(0.0, 1.0, 1, func(x float64) { v = x }),
+		NewTween(1.0, 2.0, 1, func(x float64) { v = x }),
+	).AppendCallback(func() { calls++ }).SetLoops(2, LoopRestart)
+
+	s.seek(1.5)
+	s.seek(2.5)
+	if !approx(v, 0.5) {
+		t.Errorf("second loop: %v, expected 0.5", v)
+	}
+	s.seek(4)
+	if !approx(v, 2) || calls != 2 {
+		t.Errorf("end: %v, callback calls %d", v, calls)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	a, b := 0.0, 0.0
+	done := false
+
+	p := NewParallel(
+		NewTween(0.0, 1.0, 1, func(x float64) { a = x }),
+		NewTween(0.0, 1.0, 2, func(x float64) { b = x }),
+	).OnComplete(func() { done = true })
+
+	if p.Duration() != 2 {
+		t.Fatalf("duration %v, expected 2", p.Duration())
+	}
+
+	p.seek(1.5)
+	if !approx(a, 1) || !approx(b, 0.75) || done {
+		t.Errorf("a %v, b %v, done %v", a, b, done)
+	}
+	p.seek(2)
+	if !done {
+		t.Error("group did not complete")
+	}
+}
+
+func TestParallel_Loop(t *testing.T) {
+	x := float32(0)
+	p := NewParallel(TweenTo(func() float32 { return x }, func(v float32) { x = v }, 10, 1)).
+		SetLoops(2, LoopRestart)
+
+	for _, c := range []struct{ t, x float64 }{{0.5, 5}, {1.5, 5}, {1.75, 7.5}} {
+		p.seek(c.t)
+		if !approx(float64(x), c.x) {
+			t.Errorf("t=%v: %v, expected %v", c.t, x, c.x)
+		}
+	}
+}
+
+func TestScheduler_Tween(t *testing.T) {
+	s := NewScheduler()
+
+	v := 0.0
+	task := s.Tween(nil, NewTween(0.0, 1.0, 1, func(x float64) { v = x }))
+
+	stepScheduler(s, 0.5)
+	if !approx(v, 0.5) {
+		t.Errorf("value %v, expected 0.5", v)
+	}
+	stepScheduler(s, 0.75)
+	if !approx(v, 1) || !task.Done() {
+		t.Errorf("value %v, done %v", v, task.Done())
+	}
+}
+
+func TestScheduler_TweenReplay(t *testing.T) {
+	s := NewScheduler()
+
+	x := float32(0)
+	tw := TweenTo(func() float32 { return x }, func(v float32) { x = v }, 10, 1)
+
+	s.Tween(nil, tw)
+	stepScheduler(s, 1)
+	if x != 10 {
+		t.Fatalf("x %v, expected 10", x)
+	}
+
+	x = 20
+	s.Tween(nil, tw)
+	stepScheduler(s, 0.5)
+	if x != 15 {
+		t.Errorf("replayed tween: x %v, expected 15", x)
+	}
+}
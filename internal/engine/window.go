@@ -76,7 +76,8 @@ type DisplayProperties struct {
 	Vsync      bool
 }
 
-// Window implements a GLFW-based window system.
+// Window implements a GLFW-based window system. A headless window, created
+// with NewNullWindow, never initializes GLFW or OpenGL and reports no input.
 type Window struct {
 	window            *glfw.Window
 	ortho             mgl32.Mat4
@@ -99,11 +100,21 @@ type Window struct {
 	windowResized     bool
 	shouldClose       bool
 	hasEvents         bool
+	headless          bool
 }
 
 func (w *Window) Setup() (err error) {
 	var monitor *glfw.Monitor
 
+	if w.headless {
+		w.resolution = math.ToIVec2(viper.Get("graphics.resolution"))
+		w.SetSize(w.resolution)
+
+		logrus.Debug("[Window] Headless")
+
+		return nil
+	}
+
 	if err := glfw.Init(); err != nil {
 		return err
 	}
@@ -200,6 +211,10 @@ func (w *Window) setupGL() error {
 
 // Teardown tears down the System.
 func (w *Window) Teardown() {
+	if w.headless {
+		return
+	}
+
 	glfw.Terminate()
 }
 
@@ -209,6 +224,11 @@ func (w *Window) Name() string {
 }
 
 func (w *Window) EnableVsync(enable bool) {
+	if w.headless {
+		w.vsync = enable
+		return
+	}
+
 	if enable {
 		glfw.SwapInterval(1)
 	} else {
@@ -223,6 +243,10 @@ func (w *Window) Vsync() bool {
 }
 
 func (w *Window) CenterWindow() {
+	if w.headless {
+		return
+	}
+
 	monitor := w.window.GetMonitor()
 	if monitor == nil {
 		monitor = glfw.GetPrimaryMonitor()
@@ -238,7 +262,9 @@ func (w *Window) CenterWindow() {
 func (w *Window) SetSize(size math.IVec2) {
 	w.resolution = size
 	w.aspectRatio = getRatio(w.resolution)
-	gl.Viewport(0, 0, int32(size.X()), int32(size.Y()))
+	if !w.headless {
+		gl.Viewport(0, 0, int32(size.X()), int32(size.Y()))
+	}
 	w.ortho = mgl32.Ortho2D(0, float32(w.resolution.X()), float32(w.resolution.Y()), 0)
 }
 
@@ -253,11 +279,19 @@ func (w *Window) Resolution() math.IVec2 {
 }
 
 func (w *Window) ClearBuffers() {
+	if w.headless {
+		return
+	}
+
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 }
 
 // SwapBuffers : Swap front and rear rendering buffers.
 func (w *Window) SwapBuffers() {
+	if w.headless {
+		return
+	}
+
 	w.window.SwapBuffers()
 }
 
@@ -273,6 +307,11 @@ func (w *Window) SetDisplayMode(mode DisplayMode) {
 	var monitor *glfw.Monitor
 	var refresh int
 
+	if w.headless {
+		w.displayMode = mode
+		return
+	}
+
 	posX, posY := w.window.GetPos()
 	resX := int(w.resolution.X())
 	resY := int(w.resolution.Y())
@@ -307,6 +346,10 @@ func (w *Window) SetDisplayMode(mode DisplayMode) {
 }
 
 func (w *Window) GetVideoModes() {
+	if w.headless {
+		return
+	}
+
 	monitors := glfw.GetMonitors()
 	modes := []*glfw.VidMode{}
 
@@ -405,7 +448,21 @@ func (w *Window) WindowResized() bool {
 
 func (w *Window) HandleEvents() {
 	w.clearEvents()
-	glfw.PollEvents()
+
+	if !w.headless {
+		glfw.PollEvents()
+	}
+}
+
+// Headless reports if this is a null window without a GLFW window or OpenGL
+// context.
+func (w *Window) Headless() bool {
+	return w.headless
+}
+
+// Close requests the window to close.
+func (w *Window) Close() {
+	w.shouldClose = true
 }
 
 func (w *Window) HasEvents() bool {
@@ -504,6 +561,15 @@ func NewWindow() *Window {
 	}
 }
 
+// NewNullWindow creates a headless window system. It does not open a window or
+// create an OpenGL context, and reports no input events.
+func NewNullWindow() *Window {
+	w := NewWindow()
+	w.headless = true
+
+	return w
+}
+
 func GetRecommendedVideoMode(monitor *glfw.Monitor) *glfw.VidMode {
 	modes := monitor.GetVideoModes()
 
@@ -520,7 +586,12 @@ func DefaultDisplayProperties() *DisplayProperties {
 
 // GetWindow gets the window system from the current app.
 func GetWindow() *Window {
-	return CurrentApp().MustSystem(SysNameWindow).(*Window)
+	return CurrentApp().Window()
+}
+
+// Window returns the window system of the App.
+func (a *App) Window() *Window {
+	return a.MustSystem(SysNameWindow).(*Window)
 }
 
 func getRatio(value math.IVec2) float32 {
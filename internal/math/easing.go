@@ -26,6 +26,9 @@ package math
 
 import "math"
 
+// EaseFunc maps the progress t in the range [0, 1] to an eased progress.
+type EaseFunc func(t float64) float64
+
 /* Lerp */
 
 func Lerp(a, b, t float64) float64 {
@@ -33,8 +33,13 @@ type SceneGraphListener interface {
 	OnSceneGraphUpdate()
 }
 
+// Graph is a directed acyclic graph in which every vertex has at most one
+// parent. Alongside the out-edge lists, the graph keeps a parent descriptor on
+// each vertex and an index of object IDs to descriptors so that parent and ID
+// lookups do not need to scan the vertex list.
 type Graph struct {
 	vertexList     map[VertexDescriptor]*Vertex
+	idIndex        map[uint32]VertexDescriptor
 	nextDescriptor VertexDescriptor
 	mutex          *sync.Mutex
 }
@@ -44,6 +49,7 @@ type Graph struct {
 func NewGraph() *Graph {
 	g := &Graph{
 		vertexList: make(map[VertexDescriptor]*Vertex),
+		idIndex:    make(map[uint32]VertexDescriptor),
 		mutex:      &sync.Mutex{},
 	}
 
@@ -59,13 +65,9 @@ func (g *Graph) VertexExistsWithDescriptor(vert VertexDescriptor) bool {
 }
 
 func (g *Graph) VertexExistsWithId(id uint32) bool {
-	for idx := range g.vertexList {
-		if g.vertexList[idx].data.ID() == id {
-			return true
-		}
-	}
+	_, ok := g.idIndex[id]
 
-	return false
+	return ok
 }
 
 func (g *Graph) RemoveVertex(u VertexDescriptor) error {
@@ -74,22 +76,16 @@ func (g *Graph) RemoveVertex(u VertexDescriptor) error {
 	}
 
 	// If this vertex has a parent, remove the reference.
-	parent, err := g.Parent(u)
-	if err == nil {
+	if parent := g.vertexList[u].parent; parent != 0 {
 		g.vertexList[parent].edges = removeVertexDescriptorElement(g.vertexList[parent].edges, u)
 	}
 
-	// Get list of descendant descriptors.
+	// Remove this vertex and its descendants.
 	descendants := g.DepthFirstSearch(u, true)
-
-	// Remove descendants references
 	for idx := range descendants {
-		delete(g.vertexList, descendants[idx])
+		g.deleteVertex(descendants[idx])
 	}
 
-	// Remove this vertex
-	delete(g.vertexList, u)
-
 	return nil
 }
 
@@ -99,31 +95,24 @@ func (g *Graph) MoveVertex(vert, parent VertexDescriptor) error {
 	}
 
 	if !g.VertexExistsWithDescriptor(parent) {
-		return fmt.Errorf("move vertex: parent descriptor %d does not exist", vert)
+		return fmt.Errorf("move vertex: parent descriptor %d does not exist", parent)
 	}
 
-	if !g.DescendantOf(parent, vert) {
+	if g.DescendantOf(parent, vert) {
 		return fmt.Errorf("move vertex: parent descriptor %d is a descendant of %d", parent, vert)
 	}
 
-	oldParent, err := g.Parent(vert)
-	if err != nil {
+	oldParent := g.vertexList[vert].parent
+	if oldParent == 0 {
 		return fmt.Errorf("move vertex: invalid move, descriptor %d is orphaned or is root node", vert)
 	}
 
 	// Remove existing edge.
-	// TODO: Check this for correctness.
-	for idx := range g.vertexList[oldParent].edges {
-		if g.vertexList[VertexDescriptor(idx)].descriptor == vert {
-			g.vertexList[oldParent].edges = removeVertexDescriptorElement(g.vertexList[oldParent].edges, vert)
-			break
-		}
-	}
+	g.vertexList[oldParent].edges = removeVertexDescriptorElement(g.vertexList[oldParent].edges, vert)
+	g.vertexList[vert].parent = 0
 
 	// Add new edge.
-	g.AddEdge(parent, vert)
-
-	return nil
+	return g.AddEdge(parent, vert)
 }
 
 func (g *Graph) AddVertex(object VertexNode) (VertexDescriptor, error) {
@@ -138,6 +127,7 @@ func (g *Graph) AddVertex(object VertexNode) (VertexDescriptor, error) {
 	}
 
 	g.vertexList[v.descriptor] = v
+	g.idIndex[object.ID()] = v.descriptor
 
 	return v.descriptor, nil
 }
@@ -151,10 +141,8 @@ func (g *Graph) GetVertexByObject(object VertexNode) (VertexDescriptor, error) {
 }
 
 func (g *Graph) GetVertexById(id uint32) (VertexDescriptor, error) {
-	for idx := range g.vertexList {
-		if g.vertexList[idx].data.ID() == id {
-			return g.vertexList[idx].descriptor, nil
-		}
+	if u, ok := g.idIndex[id]; ok {
+		return u, nil
 	}
 
 	return 0, fmt.Errorf("get vertex: no such vertex with id: %d", id)
@@ -179,8 +167,8 @@ func (g *Graph) NextDescriptor() (VertexDescriptor, error) {
 	id := g.nextDescriptor + 1
 	_, ok := g.vertexList[id]
 
-	for ok {
-		id = g.nextDescriptor + 1
+	for ok || id == 0 {
+		id++
 		_, ok = g.vertexList[id]
 	}
 
@@ -192,11 +180,28 @@ func (g *Graph) NextDescriptor() (VertexDescriptor, error) {
 // Edge Operations
 
 func (g *Graph) AddEdge(u, v VertexDescriptor) error {
+	if !g.VertexExistsWithDescriptor(u) {
+		return fmt.Errorf("add edge: descriptor %d does not exist", u)
+	}
+
+	if !g.VertexExistsWithDescriptor(v) {
+		return fmt.Errorf("add edge: descriptor %d does not exist", v)
+	}
+
 	if g.DescendantOf(u, v) {
 		return fmt.Errorf("add edge: %d is a descendant of %d", u, v)
 	}
 
+	switch parent := g.vertexList[v].parent; parent {
+	case u:
+		return nil
+	case 0:
+	default:
+		return fmt.Errorf("add edge: %d already has parent %d", v, parent)
+	}
+
 	g.vertexList[u].edges = append(g.vertexList[u].edges, v)
+	g.vertexList[v].parent = u
 
 	return nil
 }
@@ -217,6 +222,7 @@ func (g *Graph) RemoveEdge(edge Edge) error {
 	for idx := range g.vertexList[edge.U()].edges {
 		if g.vertexList[edge.U()].edges[idx] == edge.V() {
 			g.vertexList[edge.U()].edges = deleteVertexDescriptorElement(g.vertexList[edge.U()].edges, idx)
+			g.vertexList[edge.V()].parent = 0
 			return nil
 		}
 	}
@@ -227,21 +233,25 @@ func (g *Graph) RemoveEdge(edge Edge) error {
 // Utility Functions
 
 func (g *Graph) ParentOf(parent, descendant VertexDescriptor) bool {
-	if g.VertexExistsWithDescriptor(parent) {
+	if !g.VertexExistsWithDescriptor(parent) {
 		return false
 	}
-	if g.VertexExistsWithDescriptor(descendant) {
+	if !g.VertexExistsWithDescriptor(descendant) {
 		return false
 	}
 
-	return g.vertexDescriptorInOutEdgeList(parent, descendant)
+	return g.vertexList[descendant].parent == parent
 }
 
 func (g *Graph) DescendantOf(descendant, parent VertexDescriptor) bool {
-	descendants := g.DepthFirstSearch(parent, true)
+	if !g.VertexExistsWithDescriptor(descendant) || !g.VertexExistsWithDescriptor(parent) {
+		return false
+	}
 
-	for idx := range descendants {
-		if descendants[idx] == descendant {
+	// Walk up the parent chain of the descendant rather than searching the
+	// entire subtree of the parent.
+	for u := descendant; u != 0; u = g.vertexList[u].parent {
+		if u == parent {
 			return true
 		}
 	}
@@ -250,22 +260,9 @@ func (g *Graph) DescendantOf(descendant, parent VertexDescriptor) bool {
 }
 
 func (g *Graph) Parent(vertex VertexDescriptor) (VertexDescriptor, error) {
-	if vertex != 0 {
-		for edge := range g.vertexList {
-			if edge == vertex {
-				continue
-			}
-
-			if g.vertexDescriptorInOutEdgeList(edge, vertex) {
-				return edge, nil
-			}
-		}
-	}
-
 	v := g.getParent(vertex)
 	if v == 0 {
 		return 0, fmt.Errorf("parent: vertex %d has no parent", vertex)
-
 	}
 
 	return v, nil
@@ -274,8 +271,10 @@ func (g *Graph) Parent(vertex VertexDescriptor) (VertexDescriptor, error) {
 // Search Functions
 
 func (g *Graph) DepthFirstSearch(u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
-	nodeList := make([]VertexDescriptor, 0)
+	return g.dFS(make([]VertexDescriptor, 0), u, includeDisabled)
+}
 
+func (g *Graph) dFS(nodeList []VertexDescriptor, u VertexDescriptor, includeDisabled bool) []VertexDescriptor {
 	if !g.VertexExistsWithDescriptor(u) {
 		return nodeList
 	}
@@ -289,8 +288,7 @@ func (g *Graph) DepthFirstSearch(u VertexDescriptor, includeDisabled bool) []Ver
 	nodeList = append(nodeList, u)
 
 	for idx := range g.vertexList[u].edges {
-		newList := g.DepthFirstSearch(g.vertexList[u].edges[idx], includeDisabled)
-		nodeList = append(nodeList, newList...)
+		nodeList = g.dFS(nodeList, g.vertexList[u].edges[idx], includeDisabled)
 	}
 
 	return nodeList
@@ -376,17 +374,22 @@ func (g *Graph) getParent(u VertexDescriptor) VertexDescriptor {
 		return 0
 	}
 
-	for i := range g.vertexList {
-		if i == u {
-			continue
-		}
+	return g.vertexList[u].parent
+}
+
+// deleteVertex removes a single vertex from the vertex list and the ID index.
+// Edges are not updated.
+func (g *Graph) deleteVertex(u VertexDescriptor) {
+	v, ok := g.vertexList[u]
+	if !ok {
+		return
+	}
 
-		for j := range g.vertexList[i].edges {
-			if g.vertexList[i].edges[j] == u {
-				return g.vertexList[i].descriptor
-			}
+	if v.data != nil {
+		if d, ok := g.idIndex[v.data.ID()]; ok && d == u {
+			delete(g.idIndex, v.data.ID())
 		}
 	}
 
-	return 0
+	delete(g.vertexList, u)
 }
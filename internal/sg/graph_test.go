@@ -374,3 +374,165 @@ func TestGraph_BreadthFirstSearch(t *testing.T) {
 		t.Error("DepthFirstSearch result does not equal expected result")
 	}
 }
+
+func TestGraph_Parent(t *testing.T) {
+	g := NewGraph()
+
+	var err error
+	var obj1Desc VertexDescriptor
+	var obj2Desc VertexDescriptor
+	var obj3Desc VertexDescriptor
+
+	// Create some objects and add them to the graph.
+	if obj1Desc, err = g.AddVertex(newObject(1)); err != nil {
+		t.Error(err)
+	}
+	if obj2Desc, err = g.AddVertex(newObject(2)); err != nil {
+		t.Error(err)
+	}
+	if obj3Desc, err = g.AddVertex(newObject(3)); err != nil {
+		t.Error(err)
+	}
+
+	// Add some edges.
+	if err := g.AddEdge(obj1Desc, obj2Desc); err != nil {
+		t.Error(err)
+	}
+	if err := g.AddEdge(obj2Desc, obj3Desc); err != nil {
+		t.Error(err)
+	}
+
+	if p, err := g.Parent(obj3Desc); err != nil || p != obj2Desc {
+		t.Errorf("g.Parent(obj3Desc) expected %d, got: %d (%v)", obj2Desc, p, err)
+	}
+	if _, err := g.Parent(obj1Desc); err == nil {
+		t.Error("g.Parent(obj1Desc) expected error for root vertex")
+	}
+	if !g.ParentOf(obj1Desc, obj2Desc) {
+		t.Error("g.ParentOf(obj1Desc, obj2Desc) expected true")
+	}
+	if !g.DescendantOf(obj3Desc, obj1Desc) {
+		t.Error("g.DescendantOf(obj3Desc, obj1Desc) expected true")
+	}
+	if g.DescendantOf(obj1Desc, obj3Desc) {
+		t.Error("g.DescendantOf(obj1Desc, obj3Desc) expected false")
+	}
+
+	// Edges creating a cycle or a second parent are rejected.
+	if err := g.AddEdge(obj3Desc, obj1Desc); err == nil {
+		t.Error("g.AddEdge(obj3Desc, obj1Desc) expected cycle error")
+	}
+	if err := g.AddEdge(obj1Desc, obj3Desc); err == nil {
+		t.Error("g.AddEdge(obj1Desc, obj3Desc) expected parent error")
+	}
+
+	// Removing a vertex removes its descendants from the id index.
+	if err := g.RemoveVertex(obj2Desc); err != nil {
+		t.Error(err)
+	}
+	if g.VertexExistsWithId(3) {
+		t.Error("g.VertexExistsWithId(3) expected false after removing parent")
+	}
+	if c := len(g.ChildrenOf(obj1Desc)); c != 0 {
+		t.Error("len(g.ChildrenOf(obj1Desc)) expected 0, got:", c)
+	}
+}
+
+func TestGraph_MoveVertex(t *testing.T) {
+	g := NewGraph()
+
+	var err error
+	var obj1Desc VertexDescriptor
+	var obj2Desc VertexDescriptor
+	var obj3Desc VertexDescriptor
+
+	// Create some objects and add them to the graph.
+	if obj1Desc, err = g.AddVertex(newObject(1)); err != nil {
+		t.Error(err)
+	}
+	if obj2Desc, err = g.AddVertex(newObject(2)); err != nil {
+		t.Error(err)
+	}
+	if obj3Desc, err = g.AddVertex(newObject(3)); err != nil {
+		t.Error(err)
+	}
+
+	// Add some edges.
+	if err := g.AddEdge(obj1Desc, obj2Desc); err != nil {
+		t.Error(err)
+	}
+	if err := g.AddEdge(obj1Desc, obj3Desc); err != nil {
+		t.Error(err)
+	}
+
+	if err := g.MoveVertex(obj3Desc, obj2Desc); err != nil {
+		t.Error(err)
+	}
+	if p, _ := g.Parent(obj3Desc); p != obj2Desc {
+		t.Errorf("g.Parent(obj3Desc) expected %d, got: %d", obj2Desc, p)
+	}
+	if g.EdgeExistsUV(obj1Desc, obj3Desc) {
+		t.Error("g.EdgeExistsUV(obj1Desc, obj3Desc) expected false after move")
+	}
+	if err := g.MoveVertex(obj2Desc, obj3Desc); err == nil {
+		t.Error("g.MoveVertex(obj2Desc, obj3Desc) expected cycle error")
+	}
+}
+
+// buildGraph creates a graph with n vertices where each vertex is a child of
+// the vertex at half its index, producing a balanced binary tree.
+func buildGraph(b *testing.B, n int) (*Graph, []VertexDescriptor) {
+	g := NewGraph()
+	desc := make([]VertexDescriptor, n)
+
+	for i := 0; i < n; i++ {
+		d, err := g.AddVertex(newObject(uint32(i + 1)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		desc[i] = d
+
+		if i > 0 {
+			if err := g.AddEdge(desc[(i-1)/2], d); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	return g, desc
+}
+
+func benchmarkGraphBuild(b *testing.B, n int) {
+	for i := 0; i < b.N; i++ {
+		buildGraph(b, n)
+	}
+}
+
+func benchmarkGraphParent(b *testing.B, n int) {
+	g, desc := buildGraph(b, n)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Parent(desc[len(desc)-1-i%(len(desc)-1)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkGraphGetVertexById(b *testing.B, n int) {
+	g, _ := buildGraph(b, n)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := g.GetVertexById(uint32(n - i%n)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGraph_Build10k(b *testing.B)          { benchmarkGraphBuild(b, 10000) }
+func BenchmarkGraph_Build100k(b *testing.B)         { benchmarkGraphBuild(b, 100000) }
+func BenchmarkGraph_Parent10k(b *testing.B)         { benchmarkGraphParent(b, 10000) }
+func BenchmarkGraph_Parent100k(b *testing.B)        { benchmarkGraphParent(b, 100000) }
+func BenchmarkGraph_GetVertexById10k(b *testing.B)  { benchmarkGraphGetVertexById(b, 10000) }
+func BenchmarkGraph_GetVertexById100k(b *testing.B) { benchmarkGraphGetVertexById(b, 100000) }
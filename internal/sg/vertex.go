@@ -28,6 +28,7 @@ type Vertex struct {
 	edges      []VertexDescriptor
 	data       VertexNode
 	descriptor VertexDescriptor
+	parent     VertexDescriptor
 }
 
 type VertexNode interface {